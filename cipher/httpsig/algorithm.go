@@ -0,0 +1,112 @@
+package httpsig
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+var (
+	AlgorithmHmacSha256 = "hmac-sha256"
+	AlgorithmEd25519    = "ed25519"
+)
+
+// Signer produces the signature of a signature base with the key identified by KeyId
+type Signer interface {
+	Algorithm() string
+	KeyId() string
+	Sign(base []byte) ([]byte, error)
+}
+
+// Verifier checks the signature of a signature base
+type Verifier interface {
+	Algorithm() string
+	Verify(base, signature []byte) error
+}
+
+// KeyResolver returns the verifier of the given key id.
+// The algorithm is empty if the sender did not declare it in the signature parameters
+type KeyResolver func(kid, alg string) (Verifier, error)
+
+// NewHmacSha256 returns a symmetric key that is able to both sign and verify
+func NewHmacSha256(kid string, key []byte) (*HmacSha256, error) {
+	if len(key) == 0 {
+		return nil, ErrKeyMalformed
+	}
+	return &HmacSha256{kid: kid, key: key}, nil
+}
+
+type HmacSha256 struct {
+	kid string
+	key []byte
+}
+
+func (alg *HmacSha256) Algorithm() string {
+	return AlgorithmHmacSha256
+}
+
+func (alg *HmacSha256) KeyId() string {
+	return alg.kid
+}
+
+func (alg *HmacSha256) Sign(base []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, alg.key)
+	mac.Write(base)
+	return mac.Sum(nil), nil
+}
+
+func (alg *HmacSha256) Verify(base, signature []byte) error {
+	expected, _ := alg.Sign(base)
+	if !hmac.Equal(expected, signature) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// NewEd25519Signer returns a signer that uses the private key of an Ed25519 key pair
+func NewEd25519Signer(kid string, key ed25519.PrivateKey) (Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, ErrKeyMalformed
+	}
+	return &ed25519signer{kid: kid, key: key}, nil
+}
+
+type ed25519signer struct {
+	kid string
+	key ed25519.PrivateKey
+}
+
+func (alg *ed25519signer) Algorithm() string {
+	return AlgorithmEd25519
+}
+
+func (alg *ed25519signer) KeyId() string {
+	return alg.kid
+}
+
+func (alg *ed25519signer) Sign(base []byte) ([]byte, error) {
+	return ed25519.Sign(alg.key, base), nil
+}
+
+// NewEd25519Verifier returns a verifier that uses the public key of an Ed25519 key pair
+func NewEd25519Verifier(key ed25519.PublicKey) (Verifier, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, ErrKeyMalformed
+	}
+	return &ed25519verifier{key: key}, nil
+}
+
+type ed25519verifier struct {
+	key ed25519.PublicKey
+}
+
+func (alg *ed25519verifier) Algorithm() string {
+	return AlgorithmEd25519
+}
+
+func (alg *ed25519verifier) Verify(base, signature []byte) error {
+	if !ed25519.Verify(alg.key, base, signature) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package httpsig
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"hash"
+	"strings"
+)
+
+var digests = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// ContentDigest returns the value of the Content-Digest header (RFC 9530) of the body using SHA-256
+func ContentDigest(body []byte) string {
+	return "sha-256=:" + digest("sha-256", body) + ":"
+}
+
+// VerifyContentDigest checks the body against every supported digest of the header.
+// Digests with unknown algorithms are ignored but at least one of them must be known
+func VerifyContentDigest(header string, body []byte) error {
+	members, err := parseDictionary(header)
+	if err != nil {
+		return err
+	}
+
+	known := false
+	for _, m := range members {
+		if _, ok := digests[m.key]; !ok || m.bytes == nil {
+			continue
+		}
+		known = true
+
+		expected := digest(m.key, body)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(base64.StdEncoding.EncodeToString(m.bytes))) != 1 {
+			return ErrContentDigestMismatch
+		}
+	}
+
+	if !known {
+		return ErrContentDigestUnknown
+	}
+	return nil
+}
+
+func digest(alg string, body []byte) string {
+	h := digests[strings.ToLower(alg)]()
+	h.Write(body)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,33 @@
+package httpsig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentDigest(t *testing.T) {
+	body := []byte(`{"hello": "world"}`)
+	require.Equal(t, "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:", ContentDigest(body))
+}
+
+func TestVerifyContentDigest(t *testing.T) {
+	body := []byte(`{"hello": "world"}`)
+
+	t.Run("OK", func(st *testing.T) {
+		require.NoError(st, VerifyContentDigest(ContentDigest(body), body))
+		require.NoError(st, VerifyContentDigest("sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:", body))
+	})
+
+	t.Run("KO - malformed error", func(st *testing.T) {
+		require.ErrorIs(st, VerifyContentDigest("sha-256=", body), ErrHeaderMalformed)
+	})
+
+	t.Run("KO - unknown algorithm error", func(st *testing.T) {
+		require.ErrorIs(st, VerifyContentDigest("md5=:AAAA:", body), ErrContentDigestUnknown)
+	})
+
+	t.Run("KO - mismatch error", func(st *testing.T) {
+		require.ErrorIs(st, VerifyContentDigest(ContentDigest([]byte("{}")), body), ErrContentDigestMismatch)
+	})
+}
@@ -0,0 +1,21 @@
+package httpsig
+
+import "errors"
+
+var (
+	ErrComponentUnsupported  = errors.New("HTTPSIG.COMPONENT.UNSUPPORTED.ERROR")
+	ErrComponentMissing      = errors.New("HTTPSIG.COMPONENT.MISSING.ERROR")
+	ErrComponentRequired     = errors.New("HTTPSIG.COMPONENT.REQUIRED.ERROR")
+	ErrComponentDuplicated   = errors.New("HTTPSIG.COMPONENT.DUPLICATED.ERROR")
+	ErrHeaderMalformed       = errors.New("HTTPSIG.HEADER.MALFORMED.ERROR")
+	ErrSignatureNotFound     = errors.New("HTTPSIG.SIGNATURE.NOT_FOUND.ERROR")
+	ErrSignatureMismatch     = errors.New("HTTPSIG.SIGNATURE.MISMATCH.ERROR")
+	ErrAlgorithmMismatch     = errors.New("HTTPSIG.ALGORITHM.MISMATCH.ERROR")
+	ErrKeyNotFound           = errors.New("HTTPSIG.KEY.NOT_FOUND.ERROR")
+	ErrKeyMalformed          = errors.New("HTTPSIG.KEY.MALFORMED.ERROR")
+	ErrCreatedTooNew         = errors.New("HTTPSIG.CREATED.TOO_NEW.ERROR")
+	ErrCreatedTooOld         = errors.New("HTTPSIG.CREATED.TOO_OLD.ERROR")
+	ErrExpired               = errors.New("HTTPSIG.EXPIRED.ERROR")
+	ErrContentDigestMismatch = errors.New("HTTPSIG.CONTENT_DIGEST.MISMATCH.ERROR")
+	ErrContentDigestUnknown  = errors.New("HTTPSIG.CONTENT_DIGEST.ALGORITHM_UNKNOWN.ERROR")
+)
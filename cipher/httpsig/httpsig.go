@@ -0,0 +1,231 @@
+package httpsig
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+// Sign signs the message with the given label and components following RFC 9421,
+// then adds the Signature-Input and Signature headers to the message.
+// If the content-digest component is covered but the header is not set yet, it will be computed from the body
+func Sign(msg *Message, label string, components []string, signer Signer, withOptions ...SignOption) (*Params, error) {
+	options := &SignOptions{Clock: clock.New()}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	if label == "" {
+		label = DefaultLabel
+	}
+
+	if slices.Contains(components, ComponentContentDigest) && msg.Header.Get(HeaderContentDigest) == "" {
+		msg.Header.Set(HeaderContentDigest, ContentDigest(msg.Body))
+	}
+
+	now := options.Clock.Now()
+	params := &Params{
+		Label:      label,
+		Components: components,
+		Created:    now.Unix(),
+		Nonce:      options.Nonce,
+		Algorithm:  signer.Algorithm(),
+		KeyId:      signer.KeyId(),
+		Tag:        options.Tag,
+	}
+	if options.Expires > 0 {
+		params.Expires = now.Add(options.Expires).Unix()
+	}
+
+	base, err := SignatureBase(msg, params)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.Sign(base)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Header.Add(HeaderSignatureInput, label+"="+params.String())
+	msg.Header.Add(HeaderSignature, label+"=:"+base64.StdEncoding.EncodeToString(signature)+":")
+	return params, nil
+}
+
+// SignRequest is the shortcut of Sign for *http.Request
+func SignRequest(req *http.Request, label string, components []string, signer Signer, withOptions ...SignOption) (*Params, error) {
+	msg, err := FromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return Sign(msg, label, components, signer, withOptions...)
+}
+
+// SignSenderRequest is the shortcut of Sign for the request of the sender package
+func SignSenderRequest(req *entities.Request, label string, components []string, signer Signer, withOptions ...SignOption) (*Params, error) {
+	msg, err := FromSenderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return Sign(msg, label, components, signer, withOptions...)
+}
+
+// Verify verifies the signature of the message and returns its parameters.
+// If the content-digest component is covered, the body is also checked against the Content-Digest header
+func Verify(msg *Message, resolve KeyResolver, withOptions ...VerifyOption) (*Params, error) {
+	options := &VerifyOptions{
+		Clock:             clock.New(),
+		ToleranceDuration: ToleranceDurationDefault,
+	}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	inputs, err := parseDictionary(strings.Join(msg.Header.Values(HeaderSignatureInput), ", "))
+	if err != nil {
+		return nil, err
+	}
+	signatures, err := parseDictionary(strings.Join(msg.Header.Values(HeaderSignature), ", "))
+	if err != nil {
+		return nil, err
+	}
+
+	input, signature, err := lookup(inputs, signatures, options.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := paramsFromMember(input)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, component := range options.RequiredComponents {
+		if !slices.Contains(params.Components, component) {
+			return nil, ErrComponentRequired
+		}
+	}
+
+	if err := verifyTime(params, options); err != nil {
+		return nil, err
+	}
+
+	verifier, err := resolve(params.KeyId, params.Algorithm)
+	if err != nil {
+		return nil, errors.Join(ErrKeyNotFound, err)
+	}
+	if verifier == nil {
+		return nil, ErrKeyNotFound
+	}
+	if params.Algorithm != "" && params.Algorithm != verifier.Algorithm() {
+		return nil, ErrAlgorithmMismatch
+	}
+
+	base, err := SignatureBase(msg, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifier.Verify(base, signature.bytes); err != nil {
+		return nil, ErrSignatureMismatch
+	}
+
+	if slices.Contains(params.Components, ComponentContentDigest) {
+		if err := VerifyContentDigest(msg.Header.Get(HeaderContentDigest), msg.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	return params, nil
+}
+
+// VerifyRequest is the shortcut of Verify for *http.Request, the body of the request is restored after verification
+func VerifyRequest(req *http.Request, resolve KeyResolver, withOptions ...VerifyOption) (*Params, error) {
+	msg, err := FromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return Verify(msg, resolve, withOptions...)
+}
+
+// VerifySenderRequest is the shortcut of Verify for the request of the sender package
+func VerifySenderRequest(req *entities.Request, resolve KeyResolver, withOptions ...VerifyOption) (*Params, error) {
+	msg, err := FromSenderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return Verify(msg, resolve, withOptions...)
+}
+
+// SignatureBase builds the signature base of the message for the given parameters (RFC 9421 section 2.5)
+func SignatureBase(msg *Message, params *Params) ([]byte, error) {
+	var sb strings.Builder
+	seen := map[string]bool{}
+
+	for _, name := range params.Components {
+		if seen[name] {
+			return nil, ErrComponentDuplicated
+		}
+		seen[name] = true
+
+		value, err := msg.component(name)
+		if err != nil {
+			return nil, err
+		}
+
+		sb.WriteString(quote(name) + ": " + value + "\n")
+	}
+	sb.WriteString(quote("@signature-params") + ": " + params.String())
+
+	return []byte(sb.String()), nil
+}
+
+func lookup(inputs, signatures []member, label string) (member, member, error) {
+	var input, signature member
+	for i := range inputs {
+		if label == "" || inputs[i].key == label {
+			input = inputs[i]
+			break
+		}
+	}
+	if input.key == "" || input.components == nil {
+		return input, signature, ErrSignatureNotFound
+	}
+
+	for i := range signatures {
+		if signatures[i].key == input.key {
+			signature = signatures[i]
+			break
+		}
+	}
+	if signature.key == "" || signature.bytes == nil {
+		return input, signature, ErrSignatureNotFound
+	}
+
+	return input, signature, nil
+}
+
+func verifyTime(params *Params, options *VerifyOptions) error {
+	now := options.Clock.Now()
+
+	if params.Created > 0 {
+		created := time.Unix(params.Created, 0)
+		if created.After(now.Add(options.ToleranceDuration)) {
+			return ErrCreatedTooNew
+		}
+		if options.MaxAge > 0 && now.After(created.Add(options.MaxAge).Add(options.ToleranceDuration)) {
+			return ErrCreatedTooOld
+		}
+	}
+
+	if params.Expires > 0 && now.After(time.Unix(params.Expires, 0).Add(options.ToleranceDuration)) {
+		return ErrExpired
+	}
+
+	return nil
+}
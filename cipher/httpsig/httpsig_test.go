@@ -0,0 +1,313 @@
+package httpsig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc9421 is the request that is used by the examples of RFC 9421
+func rfc9421() *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/foo?param=Value&Pet=dog", strings.NewReader(`{"hello": "world"}`))
+	req.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Digest", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:")
+	req.Header.Set("Content-Length", "18")
+	return req
+}
+
+func TestVerify_RFC9421(t *testing.T) {
+	secret, _ := base64.StdEncoding.DecodeString("uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi6pcl8jsasjlTMtDQ==")
+	key, err := NewHmacSha256("test-shared-secret", secret)
+	require.NoError(t, err)
+
+	req := rfc9421()
+	req.Header.Set(HeaderSignatureInput, `sig-b26=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`)
+	req.Header.Set(HeaderSignature, `sig-b26=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:`)
+
+	resolve := func(kid, alg string) (Verifier, error) {
+		require.Equal(t, "test-shared-secret", kid)
+		return key, nil
+	}
+	params, err := VerifyRequest(req, resolve, VerifyClock(testify.Clock(time.Unix(1618884473, 0))))
+	require.NoError(t, err)
+	require.Equal(t, "sig-b26", params.Label)
+	require.Equal(t, []string{"date", "@authority", "content-type"}, params.Components)
+}
+
+func TestSign(t *testing.T) {
+	key, err := NewHmacSha256(uuid.NewString(), []byte(uuid.NewString()))
+	require.NoError(t, err)
+	resolve := func(kid, alg string) (Verifier, error) {
+		return key, nil
+	}
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := NewEd25519Signer("ed", private)
+	require.NoError(t, err)
+	verifier, err := NewEd25519Verifier(public)
+	require.NoError(t, err)
+
+	components := []string{ComponentMethod, ComponentTargetUri, "content-type", ComponentContentDigest}
+
+	t.Run("OK - *http.Request", func(st *testing.T) {
+		req := rfc9421()
+		req.Header.Del(HeaderContentDigest)
+
+		_, err := SignRequest(req, "", components, key, Expires(time.Minute), Nonce(uuid.NewString()), Tag("kanthor"))
+		require.NoError(st, err)
+		require.Equal(st, ContentDigest([]byte(`{"hello": "world"}`)), req.Header.Get(HeaderContentDigest))
+
+		params, err := VerifyRequest(req, resolve, RequiredComponents(ComponentContentDigest), MaxAge(time.Minute))
+		require.NoError(st, err)
+		require.Equal(st, DefaultLabel, params.Label)
+		require.Equal(st, "kanthor", params.Tag)
+		require.Equal(st, AlgorithmHmacSha256, params.Algorithm)
+
+		// the body is restored after verification
+		body, err := io.ReadAll(req.Body)
+		require.NoError(st, err)
+		require.Equal(st, `{"hello": "world"}`, string(body))
+	})
+
+	t.Run("OK - sender request", func(st *testing.T) {
+		req := &entities.Request{
+			Method:  http.MethodPost,
+			Uri:     "https://example.com/webhook?id=" + uuid.NewString(),
+			Headers: http.Header{"Content-Type": []string{"application/json"}},
+			Body:    []byte(testdata.Fake.Lorem().Sentence(10)),
+		}
+
+		_, err := SignSenderRequest(req, "partner", components, signer)
+		require.NoError(st, err)
+
+		ed := func(kid, alg string) (Verifier, error) {
+			require.Equal(st, "ed", kid)
+			require.Equal(st, AlgorithmEd25519, alg)
+			return verifier, nil
+		}
+		_, err = VerifySenderRequest(req, ed, Label("partner"))
+		require.NoError(st, err)
+	})
+
+	t.Run("OK - sender request is verified by receiver", func(st *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := VerifyRequest(r, resolve); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		req := &entities.Request{Method: http.MethodPut, Uri: server.URL + "/webhook?q=1", Body: []byte(uuid.NewString())}
+		_, err := SignSenderRequest(req, "", components[:2], key)
+		require.NoError(st, err)
+		req.Headers.Set("Content-Type", "text/plain")
+
+		r, err := http.NewRequestWithContext(context.Background(), req.Method, req.Uri, strings.NewReader(string(req.Body)))
+		require.NoError(st, err)
+		r.Header = req.Headers
+
+		res, err := http.DefaultClient.Do(r)
+		require.NoError(st, err)
+		defer res.Body.Close()
+		require.Equal(st, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("OK - multiple signatures", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "first", components, key)
+		require.NoError(st, err)
+		_, err = SignRequest(req, "second", components, signer)
+		require.NoError(st, err)
+
+		ed := func(kid, alg string) (Verifier, error) {
+			return verifier, nil
+		}
+		_, err = VerifyRequest(req, ed, Label("second"))
+		require.NoError(st, err)
+		_, err = VerifyRequest(req, resolve, Label("first"))
+		require.NoError(st, err)
+	})
+
+	t.Run("OK - headers are not modified", func(st *testing.T) {
+		req := rfc9421()
+		req.Header.Add("X-Padded", "  one ")
+		req.Header.Add("X-Padded", " two")
+
+		_, err := SignRequest(req, "", []string{"x-padded"}, key)
+		require.NoError(st, err)
+		require.Equal(st, []string{"  one ", " two"}, req.Header.Values("X-Padded"))
+
+		_, err = VerifyRequest(req, resolve)
+		require.NoError(st, err)
+		require.Equal(st, []string{"  one ", " two"}, req.Header.Values("X-Padded"))
+	})
+
+	t.Run("KO - component missing error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", []string{"x-not-found"}, key)
+		require.ErrorIs(st, err, ErrComponentMissing)
+	})
+
+	t.Run("KO - component unsupported error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", []string{"@status"}, key)
+		require.ErrorIs(st, err, ErrComponentUnsupported)
+	})
+
+	t.Run("KO - component duplicated error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", []string{ComponentMethod, ComponentMethod}, key)
+		require.ErrorIs(st, err, ErrComponentDuplicated)
+	})
+
+	t.Run("KO - sender request uri error", func(st *testing.T) {
+		_, err := SignSenderRequest(&entities.Request{Uri: ":://"}, "", components, key)
+		require.ErrorIs(st, err, ErrComponentMissing)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	key, err := NewHmacSha256(uuid.NewString(), []byte(uuid.NewString()))
+	require.NoError(t, err)
+	resolve := func(kid, alg string) (Verifier, error) {
+		return key, nil
+	}
+	components := []string{ComponentMethod, ComponentAuthority, ComponentScheme, ComponentPath, ComponentQuery, ComponentContentDigest}
+
+	t.Run("KO - signature not found error", func(st *testing.T) {
+		_, err := VerifyRequest(rfc9421(), resolve)
+		require.ErrorIs(st, err, ErrSignatureNotFound)
+
+		req := rfc9421()
+		_, err = SignRequest(req, "", components, key)
+		require.NoError(st, err)
+		_, err = VerifyRequest(req, resolve, Label("unknown"))
+		require.ErrorIs(st, err, ErrSignatureNotFound)
+
+		req.Header.Del(HeaderSignature)
+		_, err = VerifyRequest(req, resolve)
+		require.ErrorIs(st, err, ErrSignatureNotFound)
+	})
+
+	t.Run("KO - header malformed error", func(st *testing.T) {
+		req := rfc9421()
+		req.Header.Set(HeaderSignatureInput, `sig1=("@method"`)
+		req.Header.Set(HeaderSignature, `sig1=:AAAA:`)
+		_, err := VerifyRequest(req, resolve)
+		require.ErrorIs(st, err, ErrHeaderMalformed)
+
+		req.Header.Set(HeaderSignatureInput, `sig1=("@method")`)
+		req.Header.Set(HeaderSignature, `sig1=:not base64:`)
+		_, err = VerifyRequest(req, resolve)
+		require.ErrorIs(st, err, ErrHeaderMalformed)
+	})
+
+	t.Run("KO - component required error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", components[:1], key)
+		require.NoError(st, err)
+
+		_, err = VerifyRequest(req, resolve, RequiredComponents(ComponentContentDigest))
+		require.ErrorIs(st, err, ErrComponentRequired)
+	})
+
+	t.Run("KO - signature mismatch error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", components, key)
+		require.NoError(st, err)
+		req.URL.RawQuery = "param=Other"
+
+		_, err = VerifyRequest(req, resolve)
+		require.ErrorIs(st, err, ErrSignatureMismatch)
+	})
+
+	t.Run("KO - content digest mismatch error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", components, key)
+		require.NoError(st, err)
+		req.Body = io.NopCloser(strings.NewReader(`{"hello": "attacker"}`))
+
+		_, err = VerifyRequest(req, resolve)
+		require.ErrorIs(st, err, ErrContentDigestMismatch)
+	})
+
+	t.Run("KO - key not found error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", components, key)
+		require.NoError(st, err)
+
+		_, err = VerifyRequest(req, func(kid, alg string) (Verifier, error) {
+			return nil, testdata.ErrGeneric
+		})
+		require.ErrorIs(st, err, ErrKeyNotFound)
+		require.ErrorIs(st, err, testdata.ErrGeneric)
+
+		_, err = VerifyRequest(req, func(kid, alg string) (Verifier, error) {
+			return nil, nil
+		})
+		require.ErrorIs(st, err, ErrKeyNotFound)
+	})
+
+	t.Run("KO - algorithm mismatch error", func(st *testing.T) {
+		req := rfc9421()
+		_, err := SignRequest(req, "", components, key)
+		require.NoError(st, err)
+
+		public, _, _ := ed25519.GenerateKey(rand.Reader)
+		verifier, _ := NewEd25519Verifier(public)
+		_, err = VerifyRequest(req, func(kid, alg string) (Verifier, error) {
+			return verifier, nil
+		})
+		require.ErrorIs(st, err, ErrAlgorithmMismatch)
+	})
+
+	t.Run("KO - time error", func(st *testing.T) {
+		now := time.Now()
+
+		req := rfc9421()
+		_, err := SignRequest(req, "", components, key, SignClock(testify.Clock(now.Add(time.Hour))))
+		require.NoError(st, err)
+		_, err = VerifyRequest(req, resolve)
+		require.ErrorIs(st, err, ErrCreatedTooNew)
+
+		req = rfc9421()
+		_, err = SignRequest(req, "", components, key, SignClock(testify.Clock(now.Add(-time.Hour))))
+		require.NoError(st, err)
+		_, err = VerifyRequest(req, resolve, MaxAge(time.Minute))
+		require.ErrorIs(st, err, ErrCreatedTooOld)
+
+		req = rfc9421()
+		_, err = SignRequest(req, "", components, key, Expires(time.Minute), SignClock(testify.Clock(now.Add(-time.Hour))))
+		require.NoError(st, err)
+		_, err = VerifyRequest(req, resolve, ToleranceDuration(time.Second))
+		require.ErrorIs(st, err, ErrExpired)
+	})
+}
+
+func TestAlgorithm(t *testing.T) {
+	_, err := NewHmacSha256("", nil)
+	require.ErrorIs(t, err, ErrKeyMalformed)
+
+	_, err = NewEd25519Signer("", ed25519.PrivateKey{})
+	require.ErrorIs(t, err, ErrKeyMalformed)
+
+	_, err = NewEd25519Verifier(ed25519.PublicKey{})
+	require.ErrorIs(t, err, ErrKeyMalformed)
+}
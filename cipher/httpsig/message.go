@@ -0,0 +1,111 @@
+package httpsig
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+var (
+	ComponentMethod        = "@method"
+	ComponentTargetUri     = "@target-uri"
+	ComponentAuthority     = "@authority"
+	ComponentScheme        = "@scheme"
+	ComponentPath          = "@path"
+	ComponentQuery         = "@query"
+	ComponentContentDigest = "content-digest"
+)
+
+// Message is the part of an HTTP request that could be covered by a signature
+type Message struct {
+	Method string
+	Uri    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// FromRequest builds a message from either an outgoing or an incoming request.
+// The body is read and restored so the request could be used after that
+func FromRequest(req *http.Request) (*Message, error) {
+	uri := *req.URL
+	// incoming requests only have the path and the query in their url
+	if uri.Host == "" {
+		uri.Host = req.Host
+	}
+	if uri.Scheme == "" {
+		uri.Scheme = "http"
+		if req.TLS != nil {
+			uri.Scheme = "https"
+		}
+	}
+
+	msg := &Message{Method: req.Method, Uri: &uri, Header: req.Header}
+	if msg.Header == nil {
+		msg.Header = make(http.Header)
+		req.Header = msg.Header
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		msg.Body = body
+	}
+
+	return msg, nil
+}
+
+// FromSenderRequest builds a message from a request of the sender package.
+// The message shares the headers with the request so signing the message will sign the request as well
+func FromSenderRequest(req *entities.Request) (*Message, error) {
+	uri, err := url.ParseRequestURI(req.Uri)
+	if err != nil {
+		return nil, ErrComponentMissing
+	}
+	if req.Headers == nil {
+		req.Headers = make(http.Header)
+	}
+
+	return &Message{Method: req.Method, Uri: uri, Header: req.Headers, Body: req.Body}, nil
+}
+
+func (msg *Message) component(name string) (string, error) {
+	switch name {
+	case ComponentMethod:
+		return strings.ToUpper(msg.Method), nil
+	case ComponentTargetUri:
+		return msg.Uri.String(), nil
+	case ComponentAuthority:
+		return strings.ToLower(msg.Uri.Host), nil
+	case ComponentScheme:
+		return strings.ToLower(msg.Uri.Scheme), nil
+	case ComponentPath:
+		if path := msg.Uri.EscapedPath(); path != "" {
+			return path, nil
+		}
+		return "/", nil
+	case ComponentQuery:
+		return "?" + msg.Uri.RawQuery, nil
+	}
+
+	if strings.HasPrefix(name, "@") || name != strings.ToLower(name) {
+		return "", ErrComponentUnsupported
+	}
+
+	// Values returns the backing slice of the header, trim a copy so we never modify the message
+	values := slices.Clone(msg.Header.Values(name))
+	if len(values) == 0 {
+		return "", ErrComponentMissing
+	}
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return strings.Join(values, ", "), nil
+}
@@ -0,0 +1,90 @@
+package httpsig
+
+import (
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+)
+
+var (
+	ToleranceDurationDefault = time.Minute
+)
+
+type SignOptions struct {
+	Clock   clock.Clock
+	Expires time.Duration
+	Nonce   string
+	Tag     string
+}
+
+type SignOption func(option *SignOptions)
+
+// SignClock overrides the clock that is used to set the created and expires parameters
+func SignClock(c clock.Clock) SignOption {
+	return func(option *SignOptions) {
+		option.Clock = c
+	}
+}
+
+// Expires sets the expires parameter to the created time plus the given duration
+func Expires(duration time.Duration) SignOption {
+	return func(option *SignOptions) {
+		option.Expires = duration
+	}
+}
+
+func Nonce(nonce string) SignOption {
+	return func(option *SignOptions) {
+		option.Nonce = nonce
+	}
+}
+
+func Tag(tag string) SignOption {
+	return func(option *SignOptions) {
+		option.Tag = tag
+	}
+}
+
+type VerifyOptions struct {
+	Clock              clock.Clock
+	Label              string
+	RequiredComponents []string
+	MaxAge             time.Duration
+	ToleranceDuration  time.Duration
+}
+
+type VerifyOption func(option *VerifyOptions)
+
+func VerifyClock(c clock.Clock) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.Clock = c
+	}
+}
+
+// Label selects the signature to verify, by default the first one of the Signature-Input header is used
+func Label(label string) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.Label = label
+	}
+}
+
+// RequiredComponents rejects signatures that do not cover all the given components
+func RequiredComponents(components ...string) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.RequiredComponents = components
+	}
+}
+
+// MaxAge rejects signatures that were created longer than the given duration ago
+func MaxAge(duration time.Duration) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.MaxAge = duration
+	}
+}
+
+// ToleranceDuration is the allowed clock skew when checking the created and expires parameters
+func ToleranceDuration(duration time.Duration) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.ToleranceDuration = duration
+	}
+}
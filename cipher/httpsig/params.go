@@ -0,0 +1,101 @@
+package httpsig
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	HeaderSignatureInput = "Signature-Input"
+	HeaderSignature      = "Signature"
+	HeaderContentDigest  = "Content-Digest"
+	DefaultLabel         = "sig1"
+)
+
+// Params are the signature parameters that are sent in the Signature-Input header
+type Params struct {
+	Label      string
+	Components []string
+	Created    int64
+	Expires    int64
+	Nonce      string
+	Algorithm  string
+	KeyId      string
+	Tag        string
+
+	// order keeps the parameters in the same order we received them
+	// because the signature base must use the exact serialization of the sender
+	order []param
+}
+
+// String returns the serialization of the inner list that is used as both
+// the value of the Signature-Input member and the @signature-params line
+func (params *Params) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("(")
+	for i := range params.Components {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(quote(params.Components[i]))
+	}
+	sb.WriteString(")")
+
+	if params.order != nil {
+		for i := range params.order {
+			sb.WriteString(params.order[i].String())
+		}
+		return sb.String()
+	}
+
+	if params.Created > 0 {
+		sb.WriteString(";created=" + strconv.FormatInt(params.Created, 10))
+	}
+	if params.Expires > 0 {
+		sb.WriteString(";expires=" + strconv.FormatInt(params.Expires, 10))
+	}
+	if params.Nonce != "" {
+		sb.WriteString(";nonce=" + quote(params.Nonce))
+	}
+	if params.Algorithm != "" {
+		sb.WriteString(";alg=" + quote(params.Algorithm))
+	}
+	if params.KeyId != "" {
+		sb.WriteString(";keyid=" + quote(params.KeyId))
+	}
+	if params.Tag != "" {
+		sb.WriteString(";tag=" + quote(params.Tag))
+	}
+	return sb.String()
+}
+
+func paramsFromMember(m member) (*Params, error) {
+	params := &Params{Label: m.key, Components: m.components, order: m.params}
+	if params.order == nil {
+		params.order = []param{}
+	}
+
+	for _, p := range m.params {
+		var err error
+		switch p.key {
+		case "created":
+			params.Created, err = strconv.ParseInt(p.raw, 10, 64)
+		case "expires":
+			params.Expires, err = strconv.ParseInt(p.raw, 10, 64)
+		case "nonce":
+			params.Nonce = unquote(p.raw)
+		case "alg":
+			params.Algorithm = unquote(p.raw)
+		case "keyid":
+			params.KeyId = unquote(p.raw)
+		case "tag":
+			params.Tag = unquote(p.raw)
+		}
+		if err != nil {
+			return nil, ErrHeaderMalformed
+		}
+	}
+
+	return params, nil
+}
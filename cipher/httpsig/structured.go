@@ -0,0 +1,260 @@
+package httpsig
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// member is a minimal representation of a dictionary member of RFC 8941 structured fields
+// that is enough for the Signature-Input and Signature headers
+type member struct {
+	key        string
+	components []string
+	bytes      []byte
+	params     []param
+}
+
+// param keeps the raw serialization of its value so we can rebuild the signature parameters exactly as they were sent
+type param struct {
+	key string
+	raw string
+}
+
+func (p param) String() string {
+	if p.raw == "" {
+		return ";" + p.key
+	}
+	return ";" + p.key + "=" + p.raw
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func parseDictionary(s string) ([]member, error) {
+	p := &parser{s: strings.TrimSpace(s)}
+	var members []member
+
+	for p.i < len(p.s) {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		m := member{key: key}
+
+		if p.peek() == '=' {
+			p.i++
+			switch p.peek() {
+			case '(':
+				if m.components, err = p.innerList(); err != nil {
+					return nil, err
+				}
+			case ':':
+				if m.bytes, err = p.byteSequence(); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, ErrHeaderMalformed
+			}
+		}
+		if m.params, err = p.params(); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+
+		p.ows()
+		if p.i >= len(p.s) {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, ErrHeaderMalformed
+		}
+		p.i++
+		p.ows()
+		if p.i >= len(p.s) {
+			return nil, ErrHeaderMalformed
+		}
+	}
+
+	return members, nil
+}
+
+func (p *parser) peek() byte {
+	if p.i >= len(p.s) {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *parser) ows() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+func (p *parser) sp() {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+func (p *parser) key() (string, error) {
+	start := p.i
+	c := p.peek()
+	if !(c >= 'a' && c <= 'z') && c != '*' {
+		return "", ErrHeaderMalformed
+	}
+	for p.i < len(p.s) {
+		c = p.s[p.i]
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' || c == '-' || c == '.' || c == '*' {
+			p.i++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.i], nil
+}
+
+func (p *parser) str() (string, error) {
+	if p.peek() != '"' {
+		return "", ErrHeaderMalformed
+	}
+	p.i++
+
+	var sb strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		p.i++
+		switch {
+		case c == '\\':
+			if p.i >= len(p.s) || (p.s[p.i] != '"' && p.s[p.i] != '\\') {
+				return "", ErrHeaderMalformed
+			}
+			sb.WriteByte(p.s[p.i])
+			p.i++
+		case c == '"':
+			return sb.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", ErrHeaderMalformed
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", ErrHeaderMalformed
+}
+
+func (p *parser) innerList() ([]string, error) {
+	p.i++ // (
+	items := []string{}
+	for {
+		p.sp()
+		if p.peek() == ')' {
+			p.i++
+			return items, nil
+		}
+
+		item, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		// component parameters such as ;sf or ;key are not supported
+		if p.peek() == ';' {
+			return nil, ErrComponentUnsupported
+		}
+		items = append(items, item)
+
+		if c := p.peek(); c != ' ' && c != ')' {
+			return nil, ErrHeaderMalformed
+		}
+	}
+}
+
+func (p *parser) byteSequence() ([]byte, error) {
+	p.i++ // :
+	end := strings.IndexByte(p.s[p.i:], ':')
+	if end < 0 {
+		return nil, ErrHeaderMalformed
+	}
+	data, err := base64.StdEncoding.DecodeString(p.s[p.i : p.i+end])
+	if err != nil {
+		return nil, ErrHeaderMalformed
+	}
+	p.i += end + 1
+	return data, nil
+}
+
+func (p *parser) params() ([]param, error) {
+	var params []param
+	for p.peek() == ';' {
+		p.i++
+		p.sp()
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != '=' {
+			params = append(params, param{key: key})
+			continue
+		}
+		p.i++
+
+		raw, err := p.bareItem()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param{key: key, raw: raw})
+	}
+	return params, nil
+}
+
+// bareItem returns the raw text of a bare item without interpreting it
+func (p *parser) bareItem() (string, error) {
+	start := p.i
+	c := p.peek()
+	switch {
+	case c == '"':
+		if _, err := p.str(); err != nil {
+			return "", err
+		}
+	case c == ':':
+		if _, err := p.byteSequence(); err != nil {
+			return "", err
+		}
+	case c == '?':
+		p.i++
+		if c := p.peek(); c != '0' && c != '1' {
+			return "", ErrHeaderMalformed
+		}
+		p.i++
+	case c == '-' || (c >= '0' && c <= '9'):
+		p.i++
+		for p.i < len(p.s) && ((p.s[p.i] >= '0' && p.s[p.i] <= '9') || p.s[p.i] == '.') {
+			p.i++
+		}
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '*':
+		for p.i < len(p.s) && !strings.ContainsRune(" ;,()\"\t", rune(p.s[p.i])) {
+			p.i++
+		}
+	default:
+		return "", ErrHeaderMalformed
+	}
+	return p.s[start:p.i], nil
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func unquote(raw string) string {
+	if len(raw) < 2 || raw[0] != '"' {
+		return raw
+	}
+	p := &parser{s: raw}
+	s, err := p.str()
+	if err != nil {
+		return raw
+	}
+	return s
+}
@@ -0,0 +1,15 @@
+package signedurl
+
+import "errors"
+
+var (
+	ErrClockSkewTooLarge    = errors.New("SIGNEDURL.CLOCK_SKEW.TOO_LARGE.ERROR")
+	ErrUrlMalformed         = errors.New("SIGNEDURL.URL.MALFORMED.ERROR")
+	ErrExpiresMissing       = errors.New("SIGNEDURL.EXPIRES.MISSING.ERROR")
+	ErrExpiresMalformed     = errors.New("SIGNEDURL.EXPIRES.MALFORMED.ERROR")
+	ErrExpired              = errors.New("SIGNEDURL.EXPIRED.ERROR")
+	ErrKeyIdMismatch        = errors.New("SIGNEDURL.KEY_ID.MISMATCH.ERROR")
+	ErrSignatureMissing     = errors.New("SIGNEDURL.SIGNATURE.MISSING.ERROR")
+	ErrSignatureMismatch    = errors.New("SIGNEDURL.SIGNATURE.MISMATCH.ERROR")
+	ErrParamAlreadyReserved = errors.New("SIGNEDURL.PARAM.RESERVED.ERROR")
+)
@@ -0,0 +1,52 @@
+package signedurl
+
+import (
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+)
+
+var (
+	ParamExpires     = "expires"
+	ParamKeyId       = "kid"
+	ParamSignature   = "signature"
+	ClockSkewDefault = time.Second * 30
+	ClockSkewMax     = time.Minute * 5
+)
+
+type Options struct {
+	KeyId string
+	Clock clock.Clock
+}
+
+type Option func(option *Options)
+
+// KeyId attaches the key id to every signed url so the receiver knows which key set it should verify against
+func KeyId(kid string) Option {
+	return func(option *Options) {
+		option.KeyId = kid
+	}
+}
+
+func Clock(c clock.Clock) Option {
+	return func(option *Options) {
+		option.Clock = c
+	}
+}
+
+type VerifyOptions struct {
+	ClockSkew time.Duration
+}
+
+type VerifyOption func(option *VerifyOptions)
+
+// ClockSkew allows a signed url to be accepted for a short while after it is expired
+// to tolerate the clock difference between the signer and the verifier
+func ClockSkew(duration time.Duration) VerifyOption {
+	return func(option *VerifyOptions) {
+		if duration > ClockSkewMax {
+			panic(ErrClockSkewTooLarge)
+		}
+		option.ClockSkew = duration
+	}
+}
@@ -0,0 +1,23 @@
+package signedurl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions(t *testing.T) {
+	options := &VerifyOptions{}
+	require.Equal(t, time.Duration(0), options.ClockSkew)
+
+	ClockSkew(time.Minute)(options)
+	require.Equal(t, time.Minute, options.ClockSkew)
+
+	defer func() {
+		if r := recover(); r != nil {
+			require.ErrorIs(t, r.(error), ErrClockSkewTooLarge)
+		}
+	}()
+	ClockSkew(ClockSkewMax + 1)(options)
+}
@@ -0,0 +1,138 @@
+package signedurl
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/kanthorlabs/common/cipher/signature"
+	"github.com/kanthorlabs/common/clock"
+	"github.com/kanthorlabs/common/validator"
+)
+
+// New creates a signer for urls that must be tamper-proof and expire.
+// The first key is used for signing, the rest of them are only used for verification
+// so you can rotate the key by adding the new one into the beginning of the keys slice
+func New(keys []string, withOptions ...Option) (SignedUrl, error) {
+	options := &Options{
+		Clock: clock.New(),
+	}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	err := validator.Validate(
+		validator.SliceRequired("keys", keys),
+		validator.Slice(keys, func(i int, item *string) error {
+			return validator.StringRequired("keys", *item)()
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signedurl{keys: keys, options: options}, nil
+}
+
+type SignedUrl interface {
+	Sign(rawurl string, expires time.Time) (string, error)
+	Verify(rawurl string, withOptions ...VerifyOption) error
+	VerifyRequest(req *http.Request, withOptions ...VerifyOption) error
+}
+
+type signedurl struct {
+	keys    []string
+	options *Options
+}
+
+// Sign returns the url with the expires, key id and signature query parameters
+// The signature covers the escaped path and the canonical form of the query, including the expires and key id parameters
+func (su *signedurl) Sign(rawurl string, expires time.Time) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", ErrUrlMalformed
+	}
+
+	query := u.Query()
+	if query.Has(ParamExpires) || query.Has(ParamKeyId) || query.Has(ParamSignature) {
+		return "", ErrParamAlreadyReserved
+	}
+
+	query.Set(ParamExpires, strconv.FormatInt(expires.Unix(), 10))
+	if su.options.KeyId != "" {
+		query.Set(ParamKeyId, su.options.KeyId)
+	}
+
+	query.Set(ParamSignature, signature.Sign(su.keys[0], canonical(u.EscapedPath(), query)))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (su *signedurl) Verify(rawurl string, withOptions ...VerifyOption) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ErrUrlMalformed
+	}
+
+	return su.verify(u, withOptions...)
+}
+
+func (su *signedurl) VerifyRequest(req *http.Request, withOptions ...VerifyOption) error {
+	return su.verify(req.URL, withOptions...)
+}
+
+func (su *signedurl) verify(u *url.URL, withOptions ...VerifyOption) error {
+	options := &VerifyOptions{
+		ClockSkew: ClockSkewDefault,
+	}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	query := u.Query()
+
+	sign := query.Get(ParamSignature)
+	if sign == "" {
+		return ErrSignatureMissing
+	}
+	query.Del(ParamSignature)
+
+	if !query.Has(ParamExpires) {
+		return ErrExpiresMissing
+	}
+	expires, err := strconv.ParseInt(query.Get(ParamExpires), 10, 64)
+	if err != nil {
+		return ErrExpiresMalformed
+	}
+	if su.options.Clock.Now().After(time.Unix(expires, 0).Add(options.ClockSkew)) {
+		return ErrExpired
+	}
+
+	if su.options.KeyId != "" && query.Get(ParamKeyId) != su.options.KeyId {
+		return ErrKeyIdMismatch
+	}
+
+	if err := signature.VerifyAny(su.keys, canonical(u.EscapedPath(), query), sign); err != nil {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+// canonical builds the data we sign from the escaped path and the sorted query
+func canonical(path string, query url.Values) string {
+	if path == "" {
+		path = "/"
+	}
+	return path + "?" + query.Encode()
+}
+
+// KeyIdOf returns the key id of a signed url so the caller can pick the right signer to verify it
+func KeyIdOf(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(ParamKeyId)
+}
@@ -0,0 +1,190 @@
+package signedurl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		su, err := New(keys)
+		require.NoError(st, err)
+		require.NotNil(st, su)
+	})
+
+	t.Run("KO - no keys error", func(st *testing.T) {
+		_, err := New([]string{})
+		require.ErrorContains(st, err, "must not be empty")
+	})
+
+	t.Run("KO - empty key error", func(st *testing.T) {
+		_, err := New([]string{uuid.NewString(), " "})
+		require.ErrorContains(st, err, "is required")
+	})
+}
+
+func TestSignedUrl_Sign(t *testing.T) {
+	su, err := New(keys, KeyId("k1"))
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, time.Now().Add(time.Hour))
+		require.NoError(st, err)
+
+		u, err := url.Parse(signed)
+		require.NoError(st, err)
+		require.Equal(st, "k1", u.Query().Get(ParamKeyId))
+		require.NotEmpty(st, u.Query().Get(ParamExpires))
+		require.NotEmpty(st, u.Query().Get(ParamSignature))
+		require.Equal(st, "k1", KeyIdOf(signed))
+	})
+
+	t.Run("KO - url malformed error", func(st *testing.T) {
+		_, err := su.Sign(":://", time.Now().Add(time.Hour))
+		require.ErrorIs(st, err, ErrUrlMalformed)
+	})
+
+	t.Run("KO - reserved param error", func(st *testing.T) {
+		_, err := su.Sign(rawurl+"&"+ParamExpires+"=1", time.Now().Add(time.Hour))
+		require.ErrorIs(st, err, ErrParamAlreadyReserved)
+	})
+}
+
+func TestSignedUrl_Verify(t *testing.T) {
+	now := time.Now()
+	su, err := New(keys, KeyId("k1"), Clock(testify.Clock(now)))
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		require.NoError(st, su.Verify(signed))
+	})
+
+	t.Run("OK - query order does not matter", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		u, _ := url.Parse(signed)
+		u.RawQuery = reverse(u.Query())
+
+		require.NoError(st, su.Verify(u.String()))
+	})
+
+	t.Run("OK - within clock skew", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(-time.Second*10))
+		require.NoError(st, err)
+
+		require.NoError(st, su.Verify(signed, ClockSkew(time.Minute)))
+	})
+
+	t.Run("OK - rotate key", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		rotated, err := New(append([]string{uuid.NewString()}, keys...), KeyId("k1"), Clock(testify.Clock(now)))
+		require.NoError(st, err)
+		require.NoError(st, rotated.Verify(signed))
+	})
+
+	t.Run("OK - request", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		req := httptest.NewRequest(http.MethodGet, signed, nil)
+		require.NoError(st, su.VerifyRequest(req))
+	})
+
+	t.Run("KO - url malformed error", func(st *testing.T) {
+		require.ErrorIs(st, su.Verify(":://"), ErrUrlMalformed)
+	})
+
+	t.Run("KO - signature missing error", func(st *testing.T) {
+		require.ErrorIs(st, su.Verify(rawurl), ErrSignatureMissing)
+	})
+
+	t.Run("KO - expires missing error", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		u, _ := url.Parse(signed)
+		query := u.Query()
+		query.Del(ParamExpires)
+		u.RawQuery = query.Encode()
+
+		require.ErrorIs(st, su.Verify(u.String()), ErrExpiresMissing)
+	})
+
+	t.Run("KO - expires malformed error", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		u, _ := url.Parse(signed)
+		query := u.Query()
+		query.Set(ParamExpires, "xxx")
+		u.RawQuery = query.Encode()
+
+		require.ErrorIs(st, su.Verify(u.String()), ErrExpiresMalformed)
+	})
+
+	t.Run("KO - expired error", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(-ClockSkewDefault-time.Second))
+		require.NoError(st, err)
+
+		require.ErrorIs(st, su.Verify(signed), ErrExpired)
+	})
+
+	t.Run("KO - key id mismatch error", func(st *testing.T) {
+		other, err := New(keys, KeyId("k2"), Clock(testify.Clock(now)))
+		require.NoError(st, err)
+
+		signed, err := other.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		require.ErrorIs(st, su.Verify(signed), ErrKeyIdMismatch)
+	})
+
+	t.Run("KO - tampered path error", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		u, _ := url.Parse(signed)
+		u.Path = "/downloads/" + uuid.NewString()
+
+		require.ErrorIs(st, su.Verify(u.String()), ErrSignatureMismatch)
+	})
+
+	t.Run("KO - tampered query error", func(st *testing.T) {
+		signed, err := su.Sign(rawurl, now.Add(time.Minute))
+		require.NoError(st, err)
+
+		u, _ := url.Parse(signed)
+		query := u.Query()
+		query.Set(ParamExpires, "9999999999")
+		u.RawQuery = query.Encode()
+
+		require.ErrorIs(st, su.Verify(u.String()), ErrSignatureMismatch)
+	})
+}
+
+var (
+	keys   = []string{uuid.NewString(), uuid.NewString()}
+	rawurl = "https://example.com/downloads/" + uuid.NewString() + "?name=" + url.QueryEscape(testdata.Fake.Lorem().Word()) + "&format=pdf"
+)
+
+func reverse(query url.Values) string {
+	var out string
+	for k := range query {
+		out = url.QueryEscape(k) + "=" + url.QueryEscape(query.Get(k)) + "&" + out
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package token
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Claims are the registered claims of RFC 7519. Times are NumericDate values (seconds since epoch)
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	Id        string   `json:"jti,omitempty"`
+}
+
+// Audience could be either a single string or an array of strings in the payload
+type Audience []string
+
+func (aud Audience) Contains(value string) bool {
+	return slices.Contains(aud, value)
+}
+
+func (aud Audience) MarshalJSON() ([]byte, error) {
+	if len(aud) == 1 {
+		return json.Marshal(aud[0])
+	}
+	return json.Marshal([]string(aud))
+}
+
+func (aud *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*aud = multiple
+	return nil
+}
+
+// Header is the JOSE header of the token
+type Header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyId     string `json:"kid,omitempty"`
+}
+
+// Token is a verified token with the custom claims decoded into T
+type Token[T any] struct {
+	Header Header
+	Claims Claims
+	Custom T
+}
@@ -0,0 +1,36 @@
+package token
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudience(t *testing.T) {
+	t.Run("OK - single", func(st *testing.T) {
+		data, err := json.Marshal(Audience{"api"})
+		require.NoError(st, err)
+		require.Equal(st, `"api"`, string(data))
+
+		var aud Audience
+		require.NoError(st, json.Unmarshal(data, &aud))
+		require.True(st, aud.Contains("api"))
+	})
+
+	t.Run("OK - multiple", func(st *testing.T) {
+		data, err := json.Marshal(Audience{"api", "sdk"})
+		require.NoError(st, err)
+		require.Equal(st, `["api","sdk"]`, string(data))
+
+		var aud Audience
+		require.NoError(st, json.Unmarshal(data, &aud))
+		require.True(st, aud.Contains("sdk"))
+		require.False(st, aud.Contains("web"))
+	})
+
+	t.Run("KO - malformed error", func(st *testing.T) {
+		var aud Audience
+		require.Error(st, json.Unmarshal([]byte(`1`), &aud))
+	})
+}
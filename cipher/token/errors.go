@@ -0,0 +1,20 @@
+package token
+
+import "errors"
+
+var (
+	ErrKeyringEmpty         = errors.New("TOKEN.KEYRING.EMPTY.ERROR")
+	ErrKeyNotFound          = errors.New("TOKEN.KEY.NOT_FOUND.ERROR")
+	ErrKeyNotSignable       = errors.New("TOKEN.KEY.NOT_SIGNABLE.ERROR")
+	ErrKeyMalformed         = errors.New("TOKEN.KEY.MALFORMED.ERROR")
+	ErrAlgorithmUnsupported = errors.New("TOKEN.ALGORITHM.UNSUPPORTED.ERROR")
+	ErrAlgorithmMismatch    = errors.New("TOKEN.ALGORITHM.MISMATCH.ERROR")
+	ErrMalformed            = errors.New("TOKEN.MALFORMED.ERROR")
+	ErrSignatureMismatch    = errors.New("TOKEN.SIGNATURE.MISMATCH.ERROR")
+	ErrExpired              = errors.New("TOKEN.CLAIMS.EXPIRED.ERROR")
+	ErrNotYetValid          = errors.New("TOKEN.CLAIMS.NOT_YET_VALID.ERROR")
+	ErrIssuedInFuture       = errors.New("TOKEN.CLAIMS.ISSUED_IN_FUTURE.ERROR")
+	ErrExpirationRequired   = errors.New("TOKEN.CLAIMS.EXPIRATION_REQUIRED.ERROR")
+	ErrIssuerMismatch       = errors.New("TOKEN.CLAIMS.ISSUER_MISMATCH.ERROR")
+	ErrAudienceMismatch     = errors.New("TOKEN.CLAIMS.AUDIENCE_MISMATCH.ERROR")
+)
@@ -0,0 +1,129 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/kanthorlabs/common/validator"
+)
+
+var (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is a key that is identified by its id (the kid header).
+// HS256 keys use Secret, EdDSA keys use PrivateKey to sign and PublicKey to verify.
+// A verification-only EdDSA key could be created with the PublicKey only
+type Key struct {
+	Id         string
+	Algorithm  string
+	Secret     []byte
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+func (key *Key) Validate() error {
+	err := validator.Validate(
+		validator.StringRequired("TOKEN.KEY.ID", key.Id),
+		validator.StringOneOf("TOKEN.KEY.ALGORITHM", key.Algorithm, []string{AlgorithmHS256, AlgorithmEdDSA}),
+	)
+	if err != nil {
+		return err
+	}
+
+	if key.Algorithm == AlgorithmHS256 {
+		return validator.Validate(
+			validator.SliceRequired("TOKEN.KEY.SECRET", key.Secret),
+		)
+	}
+
+	if key.PublicKey == nil && len(key.PrivateKey) == ed25519.PrivateKeySize {
+		key.PublicKey = key.PrivateKey.Public().(ed25519.PublicKey)
+	}
+	return validator.Validate(
+		validator.NumberInRange("TOKEN.KEY.PUBLIC_KEY.SIZE", len(key.PublicKey), ed25519.PublicKeySize, ed25519.PublicKeySize),
+	)
+}
+
+func (key *Key) signable() bool {
+	if key.Algorithm == AlgorithmHS256 {
+		return len(key.Secret) > 0
+	}
+	return len(key.PrivateKey) == ed25519.PrivateKeySize
+}
+
+func (key *Key) verifiable() bool {
+	if key.Algorithm == AlgorithmHS256 {
+		return len(key.Secret) > 0
+	}
+	return len(key.PublicKey) == ed25519.PublicKeySize
+}
+
+func (key *Key) sign(data []byte) []byte {
+	if key.Algorithm == AlgorithmHS256 {
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write(data)
+		return mac.Sum(nil)
+	}
+	return ed25519.Sign(key.PrivateKey, data)
+}
+
+func (key *Key) verify(data, signature []byte) bool {
+	if key.Algorithm == AlgorithmHS256 {
+		return hmac.Equal(key.sign(data), signature)
+	}
+	return ed25519.Verify(key.PublicKey, data, signature)
+}
+
+// Keyring provides the key to sign new tokens and looks up keys by their id to verify tokens
+type Keyring interface {
+	Signing() (*Key, error)
+	Lookup(kid string) (*Key, error)
+}
+
+// NewKeyring creates an in-memory keyring.
+// The first key is used for signing, the rest of them are only used for verification
+// so you can rotate the key by adding the new one into the beginning of the keys
+func NewKeyring(keys ...Key) (Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrKeyringEmpty
+	}
+
+	ring := &keyring{signing: keys[0].Id, keys: make(map[string]*Key, len(keys))}
+	for i := range keys {
+		key := keys[i]
+		if err := key.Validate(); err != nil {
+			return nil, err
+		}
+		if _, exist := ring.keys[key.Id]; exist {
+			return nil, fmt.Errorf("TOKEN.KEYRING.DUPLICATED_KEY.ERROR: %s", key.Id)
+		}
+		ring.keys[key.Id] = &key
+	}
+
+	return ring, nil
+}
+
+type keyring struct {
+	signing string
+	keys    map[string]*Key
+}
+
+func (ring *keyring) Signing() (*Key, error) {
+	key := ring.keys[ring.signing]
+	if !key.signable() {
+		return nil, ErrKeyNotSignable
+	}
+	return key, nil
+}
+
+func (ring *keyring) Lookup(kid string) (*Key, error) {
+	key, exist := ring.keys[kid]
+	if !exist {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
@@ -0,0 +1,36 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeyring(t *testing.T) {
+	t.Run("KO - empty error", func(st *testing.T) {
+		_, err := NewKeyring()
+		require.ErrorIs(st, err, ErrKeyringEmpty)
+	})
+
+	t.Run("KO - key validation error", func(st *testing.T) {
+		_, err := NewKeyring(Key{Algorithm: AlgorithmHS256})
+		require.ErrorContains(st, err, "TOKEN.KEY.ID")
+
+		_, err = NewKeyring(Key{Id: "rs", Algorithm: "RS256"})
+		require.ErrorContains(st, err, "TOKEN.KEY.ALGORITHM")
+
+		_, err = NewKeyring(Key{Id: "hs", Algorithm: AlgorithmHS256})
+		require.ErrorContains(st, err, "TOKEN.KEY.SECRET")
+
+		_, err = NewKeyring(Key{Id: "ed", Algorithm: AlgorithmEdDSA, PublicKey: ed25519.PublicKey{}})
+		require.ErrorContains(st, err, "TOKEN.KEY.PUBLIC_KEY")
+	})
+
+	t.Run("KO - duplicated key error", func(st *testing.T) {
+		key := Key{Id: "hs", Algorithm: AlgorithmHS256, Secret: []byte(uuid.NewString())}
+		_, err := NewKeyring(key, key)
+		require.ErrorContains(st, err, "TOKEN.KEYRING.DUPLICATED_KEY.ERROR")
+	})
+}
@@ -0,0 +1,84 @@
+package token
+
+import (
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+)
+
+var (
+	TypeDefault      = "JWT"
+	ClockSkewDefault = time.Second * 30
+)
+
+type IssueOptions struct {
+	Clock      clock.Clock
+	TimeToLive time.Duration
+	Type       string
+}
+
+type IssueOption func(option *IssueOptions)
+
+func IssueClock(c clock.Clock) IssueOption {
+	return func(option *IssueOptions) {
+		option.Clock = c
+	}
+}
+
+// TimeToLive sets the exp claim relatively to the iat claim if it is not set yet
+func TimeToLive(duration time.Duration) IssueOption {
+	return func(option *IssueOptions) {
+		option.TimeToLive = duration
+	}
+}
+
+// Type overrides the typ header, the default value is JWT
+func Type(typ string) IssueOption {
+	return func(option *IssueOptions) {
+		option.Type = typ
+	}
+}
+
+type VerifyOptions struct {
+	Clock             clock.Clock
+	ClockSkew         time.Duration
+	Issuer            string
+	Audience          string
+	RequireExpiration bool
+}
+
+type VerifyOption func(option *VerifyOptions)
+
+func VerifyClock(c clock.Clock) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.Clock = c
+	}
+}
+
+// ClockSkew is the tolerance that is applied to the exp, nbf and iat claims
+func ClockSkew(duration time.Duration) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.ClockSkew = duration
+	}
+}
+
+// ExpectedIssuer rejects tokens that are not issued by the given issuer
+func ExpectedIssuer(iss string) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.Issuer = iss
+	}
+}
+
+// ExpectedAudience rejects tokens that are not intended for the given audience
+func ExpectedAudience(aud string) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.Audience = aud
+	}
+}
+
+// RequireExpiration rejects tokens without the exp claim
+func RequireExpiration() VerifyOption {
+	return func(option *VerifyOptions) {
+		option.RequireExpiration = true
+	}
+}
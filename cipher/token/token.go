@@ -0,0 +1,169 @@
+package token
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+)
+
+// Issue signs the claims and the custom claims with the signing key of the keyring
+// and returns a JWS compact token. The registered claims take precedence over custom claims with the same name.
+// If the iat claim is not set, it will be set to the current time of the clock
+func Issue[T any](ring Keyring, claims Claims, custom T, withOptions ...IssueOption) (string, error) {
+	options := &IssueOptions{Clock: clock.New(), Type: TypeDefault}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	key, err := ring.Signing()
+	if err != nil {
+		return "", err
+	}
+
+	if claims.IssuedAt == 0 {
+		claims.IssuedAt = options.Clock.Now().Unix()
+	}
+	if claims.ExpiresAt == 0 && options.TimeToLive > 0 {
+		claims.ExpiresAt = time.Unix(claims.IssuedAt, 0).Add(options.TimeToLive).Unix()
+	}
+
+	header, err := json.Marshal(Header{Algorithm: key.Algorithm, Type: options.Type, KeyId: key.Id})
+	if err != nil {
+		return "", err
+	}
+	payload, err := merge(claims, custom)
+	if err != nil {
+		return "", err
+	}
+
+	data := encode(header) + "." + encode(payload)
+	return data + "." + encode(key.sign([]byte(data))), nil
+}
+
+// Verify checks the signature of the token with the key of its kid header
+// then validates the registered claims and decodes the custom claims into T
+func Verify[T any](ring Keyring, token string, withOptions ...VerifyOption) (*Token[T], error) {
+	options := &VerifyOptions{Clock: clock.New(), ClockSkew: ClockSkewDefault}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var out Token[T]
+	if err := decode(segments[0], &out.Header); err != nil {
+		return nil, err
+	}
+	if out.Header.Algorithm != AlgorithmHS256 && out.Header.Algorithm != AlgorithmEdDSA {
+		return nil, ErrAlgorithmUnsupported
+	}
+
+	key, err := ring.Lookup(out.Header.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	// never trust the algorithm of the header, it must be the one we registered for the key
+	if key.Algorithm != out.Header.Algorithm {
+		return nil, ErrAlgorithmMismatch
+	}
+	// keyrings are pluggable, anyone could forge HMACs of an empty secret and ed25519.Verify panics with a public key of the wrong size
+	if !key.verifiable() {
+		return nil, ErrKeyMalformed
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if !key.verify([]byte(segments[0]+"."+segments[1]), signature) {
+		return nil, ErrSignatureMismatch
+	}
+
+	if err := decode(segments[1], &out.Claims); err != nil {
+		return nil, err
+	}
+	if err := decode(segments[1], &out.Custom); err != nil {
+		return nil, err
+	}
+
+	if err := validate(&out.Claims, options); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func validate(claims *Claims, options *VerifyOptions) error {
+	now := options.Clock.Now()
+
+	if claims.ExpiresAt == 0 && options.RequireExpiration {
+		return ErrExpirationRequired
+	}
+	if claims.ExpiresAt > 0 && !now.Before(time.Unix(claims.ExpiresAt, 0).Add(options.ClockSkew)) {
+		return ErrExpired
+	}
+	if claims.NotBefore > 0 && now.Before(time.Unix(claims.NotBefore, 0).Add(-options.ClockSkew)) {
+		return ErrNotYetValid
+	}
+	if claims.IssuedAt > 0 && now.Before(time.Unix(claims.IssuedAt, 0).Add(-options.ClockSkew)) {
+		return ErrIssuedInFuture
+	}
+
+	if options.Issuer != "" && claims.Issuer != options.Issuer {
+		return ErrIssuerMismatch
+	}
+	if options.Audience != "" && !claims.Audience.Contains(options.Audience) {
+		return ErrAudienceMismatch
+	}
+
+	return nil
+}
+
+func merge[T any](claims Claims, custom T) ([]byte, error) {
+	registered, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(custom)
+	if err != nil {
+		return nil, err
+	}
+	// custom claims that are not a JSON object (nil, number, etc.) are ignored
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return registered, nil
+	}
+
+	payload := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(registered, &payload); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(payload)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(segment string, dest any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+type session struct {
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
+}
+
+func TestIssue(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		ring := hs256ring(st)
+		now := time.Now()
+
+		tok, err := Issue(ring, Claims{Subject: "u_1"}, session{Role: "admin"}, IssueClock(testify.Clock(now)), TimeToLive(time.Minute))
+		require.NoError(st, err)
+		require.Len(st, strings.Split(tok, "."), 3)
+
+		out, err := Verify[session](ring, tok, VerifyClock(testify.Clock(now)))
+		require.NoError(st, err)
+		require.Equal(st, now.Unix(), out.Claims.IssuedAt)
+		require.Equal(st, now.Add(time.Minute).Unix(), out.Claims.ExpiresAt)
+		require.Equal(st, TypeDefault, out.Header.Type)
+	})
+
+	t.Run("OK - registered claims take precedence", func(st *testing.T) {
+		ring := hs256ring(st)
+
+		tok, err := Issue(ring, Claims{Subject: "u_1"}, map[string]any{"sub": "u_2", "tenant": "t_1"})
+		require.NoError(st, err)
+
+		out, err := Verify[map[string]any](ring, tok)
+		require.NoError(st, err)
+		require.Equal(st, "u_1", out.Claims.Subject)
+		require.Equal(st, "u_1", out.Custom["sub"])
+		require.Equal(st, "t_1", out.Custom["tenant"])
+	})
+
+	t.Run("OK - without custom claims", func(st *testing.T) {
+		ring := hs256ring(st)
+
+		tok, err := Issue[any](ring, Claims{Id: idx.New("jti")}, nil, Type("at+jwt"))
+		require.NoError(st, err)
+
+		out, err := Verify[struct{}](ring, tok)
+		require.NoError(st, err)
+		require.Equal(st, "at+jwt", out.Header.Type)
+	})
+
+	t.Run("KO - not signable error", func(st *testing.T) {
+		public, _, _ := ed25519.GenerateKey(rand.Reader)
+		ring, err := NewKeyring(Key{Id: "pub", Algorithm: AlgorithmEdDSA, PublicKey: public})
+		require.NoError(st, err)
+
+		_, err = Issue(ring, Claims{}, session{})
+		require.ErrorIs(st, err, ErrKeyNotSignable)
+	})
+
+	t.Run("KO - custom claims marshal error", func(st *testing.T) {
+		_, err := Issue(hs256ring(st), Claims{}, map[string]any{"ch": make(chan int)})
+		require.Error(st, err)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	watch := testify.Clock(now)
+
+	t.Run("OK - EdDSA", func(st *testing.T) {
+		ring := eddsaring(st)
+
+		claims := Claims{Issuer: "kanthor", Audience: Audience{"api", "sdk"}, Subject: "u_1", NotBefore: now.Unix()}
+		tok, err := Issue(ring, claims, session{Role: "viewer", Scopes: []string{"read"}}, IssueClock(watch))
+		require.NoError(st, err)
+
+		out, err := Verify[session](ring, tok, VerifyClock(watch), ExpectedIssuer("kanthor"), ExpectedAudience("sdk"))
+		require.NoError(st, err)
+		require.Equal(st, "viewer", out.Custom.Role)
+		require.Equal(st, []string{"read"}, out.Custom.Scopes)
+		require.Equal(st, AlgorithmEdDSA, out.Header.Algorithm)
+	})
+
+	t.Run("OK - rotate key", func(st *testing.T) {
+		old := Key{Id: "old", Algorithm: AlgorithmHS256, Secret: []byte(uuid.NewString())}
+		ring, err := NewKeyring(old)
+		require.NoError(st, err)
+		tok, err := Issue(ring, Claims{}, session{})
+		require.NoError(st, err)
+
+		rotated, err := NewKeyring(Key{Id: "new", Algorithm: AlgorithmHS256, Secret: []byte(uuid.NewString())}, old)
+		require.NoError(st, err)
+		_, err = Verify[session](rotated, tok)
+		require.NoError(st, err)
+	})
+
+	t.Run("KO - malformed error", func(st *testing.T) {
+		ring := hs256ring(st)
+		tok, err := Issue(ring, Claims{}, session{})
+		require.NoError(st, err)
+		segments := strings.Split(tok, ".")
+
+		_, err = Verify[session](ring, "a.b")
+		require.ErrorIs(st, err, ErrMalformed)
+
+		_, err = Verify[session](ring, "!!."+segments[1]+"."+segments[2])
+		require.ErrorIs(st, err, ErrMalformed)
+
+		_, err = Verify[session](ring, segments[0]+"."+segments[1]+".!!")
+		require.ErrorIs(st, err, ErrMalformed)
+	})
+
+	t.Run("KO - algorithm unsupported error", func(st *testing.T) {
+		ring := hs256ring(st)
+		tok, err := Issue(ring, Claims{}, session{})
+		require.NoError(st, err)
+		segments := strings.Split(tok, ".")
+
+		none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"hs"}`))
+		_, err = Verify[session](ring, none+"."+segments[1]+".")
+		require.ErrorIs(st, err, ErrAlgorithmUnsupported)
+	})
+
+	t.Run("KO - algorithm mismatch error", func(st *testing.T) {
+		ring := hs256ring(st)
+		tok, err := Issue(ring, Claims{}, session{})
+		require.NoError(st, err)
+		segments := strings.Split(tok, ".")
+
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA","kid":"hs"}`))
+		_, err = Verify[session](ring, header+"."+segments[1]+"."+segments[2])
+		require.ErrorIs(st, err, ErrAlgorithmMismatch)
+	})
+
+	t.Run("KO - key not found error", func(st *testing.T) {
+		tok, err := Issue(hs256ring(st), Claims{}, session{})
+		require.NoError(st, err)
+
+		_, err = Verify[session](eddsaring(st), tok)
+		require.ErrorIs(st, err, ErrKeyNotFound)
+	})
+
+	t.Run("KO - key malformed error", func(st *testing.T) {
+		for _, ring := range []Keyring{eddsaring(st), hs256ring(st)} {
+			tok, err := Issue(ring, Claims{}, session{})
+			require.NoError(st, err)
+
+			_, err = Verify[session](&shortkeyring{Keyring: ring}, tok)
+			require.ErrorIs(st, err, ErrKeyMalformed)
+		}
+	})
+
+	t.Run("KO - signature mismatch error", func(st *testing.T) {
+		ring := hs256ring(st)
+		tok, err := Issue(ring, Claims{Subject: "u_1"}, session{})
+		require.NoError(st, err)
+		forged, err := Issue(ring, Claims{Subject: "u_2"}, session{})
+		require.NoError(st, err)
+
+		segments := strings.Split(tok, ".")
+		_, err = Verify[session](ring, segments[0]+"."+strings.Split(forged, ".")[1]+"."+segments[2])
+		require.ErrorIs(st, err, ErrSignatureMismatch)
+	})
+
+	t.Run("KO - claims error", func(st *testing.T) {
+		ring := hs256ring(st)
+		testcases := map[error]struct {
+			claims  Claims
+			options []VerifyOption
+		}{
+			ErrExpired:            {claims: Claims{ExpiresAt: now.Add(-time.Minute).Unix()}},
+			ErrNotYetValid:        {claims: Claims{NotBefore: now.Add(time.Minute).Unix()}},
+			ErrIssuedInFuture:     {claims: Claims{IssuedAt: now.Add(time.Minute).Unix()}},
+			ErrExpirationRequired: {claims: Claims{}, options: []VerifyOption{RequireExpiration()}},
+			ErrIssuerMismatch:     {claims: Claims{Issuer: "other"}, options: []VerifyOption{ExpectedIssuer("kanthor")}},
+			ErrAudienceMismatch:   {claims: Claims{Audience: Audience{"other"}}, options: []VerifyOption{ExpectedAudience("api")}},
+		}
+
+		for expected, testcase := range testcases {
+			tok, err := Issue(ring, testcase.claims, session{}, IssueClock(watch))
+			require.NoError(st, err)
+
+			options := append([]VerifyOption{VerifyClock(watch)}, testcase.options...)
+			_, err = Verify[session](ring, tok, options...)
+			require.ErrorIs(st, err, expected)
+		}
+	})
+
+	t.Run("OK - within clock skew", func(st *testing.T) {
+		ring := hs256ring(st)
+		tok, err := Issue(ring, Claims{ExpiresAt: now.Add(-time.Second * 10).Unix()}, session{})
+		require.NoError(st, err)
+
+		_, err = Verify[session](ring, tok, VerifyClock(watch), ClockSkew(time.Minute))
+		require.NoError(st, err)
+	})
+}
+
+func hs256ring(t *testing.T) Keyring {
+	ring, err := NewKeyring(Key{Id: "hs", Algorithm: AlgorithmHS256, Secret: []byte(uuid.NewString())})
+	require.NoError(t, err)
+	return ring
+}
+
+func eddsaring(t *testing.T) Keyring {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ring, err := NewKeyring(Key{Id: "ed", Algorithm: AlgorithmEdDSA, PrivateKey: private})
+	require.NoError(t, err)
+	return ring
+}
+
+// shortkeyring returns keys whose secret or public key is truncated like a broken keyring backend would do
+type shortkeyring struct {
+	Keyring
+}
+
+func (ring *shortkeyring) Lookup(kid string) (*Key, error) {
+	key, err := ring.Keyring.Lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	short := *key
+	if short.Algorithm == AlgorithmHS256 {
+		short.Secret = short.Secret[:0]
+	} else {
+		short.PublicKey = short.PublicKey[:8]
+	}
+	return &short, nil
+}
@@ -0,0 +1,8 @@
+package circuitbreaker
+
+import "errors"
+
+var (
+	ErrTooManyRequests = errors.New("CIRCUIT_BREAKER.TOO_MANY_REQUEST.ERROR")
+	ErrOpenState       = errors.New("CIRCUIT_BREAKER.STAGE_OPEN.ERROR")
+)
@@ -92,10 +92,10 @@ func (cb *sonycb) get(cmd string, onError ErrorHandler) *gobreaker.CircuitBreake
 
 func (cb *sonycb) error(err error) error {
 	if errors.Is(err, gobreaker.ErrTooManyRequests) {
-		return errors.New("CIRCUIT_BREAKER.TOO_MANY_REQUEST.ERROR")
+		return ErrTooManyRequests
 	}
 	if errors.Is(err, gobreaker.ErrOpenState) {
-		return errors.New("CIRCUIT_BREAKER.STAGE_OPEN.ERROR")
+		return ErrOpenState
 	}
 	return err
 }
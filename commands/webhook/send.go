@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/logging"
+	"github.com/kanthorlabs/common/sender"
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/spf13/cobra"
+)
+
+func NewSend() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "send",
+		Short: "send a signed message through the sender",
+		Args:  cobra.MatchAll(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wh, standard, err := instance(cmd)
+			if err != nil {
+				return err
+			}
+			uri, err := cmd.Flags().GetString("uri")
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetString("id")
+			if err != nil {
+				return err
+			}
+			headers, err := cmd.Flags().GetStringArray("header")
+			if err != nil {
+				return err
+			}
+			timeout, err := cmd.Flags().GetDuration("timeout")
+			if err != nil {
+				return err
+			}
+			ts, err := timestamp(cmd, standard)
+			if err != nil {
+				return err
+			}
+			data, err := body(cmd)
+			if err != nil {
+				return err
+			}
+
+			// sign the message at the timestamp of the flag so we could replay a message that is sent before
+			signed, err := http.NewRequest(http.MethodPost, uri, bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			if err := wh.SignRequest(signed, id, ts); err != nil {
+				return err
+			}
+			sealed, err := io.ReadAll(signed.Body)
+			if err != nil {
+				return err
+			}
+
+			logger, err := logging.NewNoop()
+			if err != nil {
+				return err
+			}
+			send, err := sender.New(&config.Config{Timeout: timeout.Milliseconds(), Retry: config.Retry{WaitTime: 500}}, logger)
+			if err != nil {
+				return err
+			}
+
+			req := &entities.Request{
+				Method:  http.MethodPost,
+				Uri:     uri,
+				Headers: signed.Header,
+				Body:    sealed,
+			}
+			if req.Headers.Get("Content-Type") == "" {
+				req.Headers.Set("Content-Type", "application/json")
+			}
+			for _, header := range headers {
+				name, value, found := strings.Cut(header, ":")
+				if !found {
+					return fmt.Errorf("header %q must be in the name: value format", header)
+				}
+				req.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+			}
+
+			res, err := send(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%d %s\n%s\n", res.Status, res.StatusText(), res.Body)
+			if !res.Ok() {
+				return fmt.Errorf("the endpoint responded %s", res.StatusText())
+			}
+			return nil
+		},
+	}
+	command.Flags().StringP("uri", "u", "", "the uri of the endpoint")
+	command.Flags().String("id", idx.New("msg"), "the id of the message")
+	command.Flags().Int64("timestamp", 0, "the timestamp of the message, in seconds in the standard mode and in milliseconds otherwise, default to now")
+	command.Flags().StringP("body", "b", "", "the path to the file that contains the body, - to read from stdin")
+	command.Flags().StringArrayP("header", "H", []string{}, "additional headers in the name: value format")
+	command.Flags().Duration("timeout", time.Second*10, "the timeout of the request")
+	command.MarkFlagRequired("uri")
+
+	return command
+}
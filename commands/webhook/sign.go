@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/spf13/cobra"
+)
+
+func NewSign() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "sign",
+		Short: "print the signed content and the signature headers of a message",
+		Args:  cobra.MatchAll(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wh, standard, err := instance(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := cmd.Flags().GetString("id")
+			if err != nil {
+				return err
+			}
+			ts, err := timestamp(cmd, standard)
+			if err != nil {
+				return err
+			}
+			data, err := body(cmd)
+			if err != nil {
+				return err
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			if err := wh.SignRequest(req, id, ts); err != nil {
+				return err
+			}
+
+			printsigned(cmd.OutOrStdout(), wh, req.Header, data)
+			return nil
+		},
+	}
+	command.Flags().String("id", idx.New("msg"), "the id of the message")
+	command.Flags().Int64("timestamp", 0, "the timestamp of the message, in seconds in the standard mode and in milliseconds otherwise, default to now")
+	command.Flags().StringP("body", "b", "", "the path to the file that contains the body, - to read from stdin")
+
+	return command
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/kanthorlabs/common/webhook"
+	"github.com/spf13/cobra"
+)
+
+func NewVerify() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "verify",
+		Short: "verify a captured raw HTTP request",
+		Args:  cobra.MatchAll(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wh, _, err := instance(cmd)
+			if err != nil {
+				return err
+			}
+			path, err := cmd.Flags().GetString("request")
+			if err != nil {
+				return err
+			}
+			tolerance, err := cmd.Flags().GetDuration("tolerance")
+			if err != nil {
+				return err
+			}
+			if tolerance < 0 || (tolerance > 0 && tolerance < webhook.ToleranceDurationMin) {
+				return fmt.Errorf("%w: --tolerance must be 0 or at least %s", webhook.ErrTimestampToleranceDurationTooSmall, webhook.ToleranceDurationMin)
+			}
+
+			var raw []byte
+			if path == "-" {
+				raw, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				raw, err = os.ReadFile(path)
+			}
+			if err != nil {
+				return err
+			}
+
+			req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(raw)))
+			if err != nil {
+				return err
+			}
+			data, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(data))
+
+			printsigned(cmd.OutOrStdout(), wh, req.Header, data)
+
+			options := []webhook.VerifyOption{webhook.TimestampToleranceIgnore()}
+			if tolerance > 0 {
+				options = []webhook.VerifyOption{webhook.TimestampToleranceDuration(tolerance)}
+			}
+			if err := wh.Verify(req, options...); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "\nverified")
+			return nil
+		},
+	}
+	command.Flags().StringP("request", "r", "", "the path to the file that contains the raw HTTP request, - to read from stdin")
+	command.Flags().Duration("tolerance", 0, "the timestamp tolerance duration, the timestamp is not checked by default because captured requests are usually old")
+	command.MarkFlagRequired("request")
+
+	return command
+}
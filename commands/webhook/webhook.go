@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/kanthorlabs/common/webhook"
+	"github.com/spf13/cobra"
+)
+
+func New() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "webhook",
+		Short: "sign, verify or send webhook messages to debug signature mismatches",
+	}
+
+	command.PersistentFlags().StringSliceP("key", "k", []string{}, "the keys of the endpoint, the first one is the newest one")
+	command.PersistentFlags().Bool("standard", false, "follow the Standard Webhooks specification")
+	command.PersistentFlags().String("namespace", "", "the namespace of the keys, default to epsec or whsec in the standard mode")
+	command.MarkPersistentFlagRequired("key")
+
+	command.AddCommand(NewSign())
+	command.AddCommand(NewVerify())
+	command.AddCommand(NewSend())
+	return command
+}
+
+func instance(cmd *cobra.Command) (webhook.Webhook, bool, error) {
+	keys, err := cmd.Flags().GetStringSlice("key")
+	if err != nil {
+		return nil, false, err
+	}
+	standard, err := cmd.Flags().GetBool("standard")
+	if err != nil {
+		return nil, false, err
+	}
+	ns, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return nil, false, err
+	}
+
+	var options []webhook.Option
+	if standard {
+		options = append(options, webhook.Standard())
+	}
+	if ns != "" {
+		options = append(options, webhook.KeyNamespace(ns))
+	}
+
+	wh, err := webhook.New(keys, options...)
+	return wh, standard, err
+}
+
+// body reads the body from the file of the body flag, - means stdin
+func body(cmd *cobra.Command) ([]byte, error) {
+	path, err := cmd.Flags().GetString("body")
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return []byte{}, nil
+	}
+	if path == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(path)
+}
+
+// timestamp converts the timestamp flag to time, it is in seconds in the standard mode and in milliseconds otherwise
+func timestamp(cmd *cobra.Command, standard bool) (time.Time, error) {
+	ts, err := cmd.Flags().GetInt64("timestamp")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ts == 0 {
+		return time.Now(), nil
+	}
+	if standard {
+		return time.Unix(ts, 0), nil
+	}
+	return time.UnixMilli(ts), nil
+}
+
+func printsigned(w io.Writer, wh webhook.Webhook, headers http.Header, data []byte) {
+	id := headers.Get(webhook.HeaderId)
+	ts := headers.Get(webhook.HeaderTimestamp)
+	body := webhook.SignedBody(headers.Get(webhook.HeaderEncryption), string(data))
+
+	fmt.Fprintln(w, "signed content:")
+	fmt.Fprintf(w, "%s.%s.%s\n\n", id, ts, body)
+
+	fmt.Fprintln(w, "expected signatures:")
+	for _, signature := range wh.Sign(id, ts, body) {
+		fmt.Fprintln(w, signature)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "headers:")
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, value)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/utils"
+	"github.com/kanthorlabs/common/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		out, err := execute(st, "", "sign", "--key", key, "--id", "msg_1", "--timestamp", "1700000000000", "--body", "-")
+		require.NoError(st, err)
+		require.Contains(st, out, "msg_1.1700000000000.{\"say\":\"hello\"}")
+		require.Contains(st, out, "Webhook-Signature: v1,")
+	})
+
+	t.Run("OK - standard", func(st *testing.T) {
+		out, err := execute(st, `{"test": 2432232314}`, "sign", "--standard", "--key", "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", "--id", "msg_p5jXN8AQM9LWM0D4loKWxJek", "--timestamp", "1614265330", "--body", "-")
+		require.NoError(st, err)
+		require.Contains(st, out, "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=")
+	})
+
+	t.Run("KO - key error", func(st *testing.T) {
+		_, err := execute(st, "", "sign", "--key", "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw")
+		require.ErrorContains(st, err, "keys[0]")
+	})
+}
+
+func TestVerify(t *testing.T) {
+	wh, err := webhook.New([]string{key})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+	req.Header = wh.Headers(idx.New("msg"), payload)
+	req.Header.Set("Content-Length", strconv.Itoa(len(payload)))
+	raw, err := httputil.DumpRequest(req, true)
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		path := filepath.Join(st.TempDir(), "request.http")
+		require.NoError(st, os.WriteFile(path, raw, 0644))
+
+		out, err := execute(st, "", "verify", "--key", key, "--request", path)
+		require.NoError(st, err)
+		require.Contains(st, out, "verified")
+	})
+
+	t.Run("KO - signature mismatch error", func(st *testing.T) {
+		another := idx.Build(webhook.DefaultKeyNs, utils.RandomString(128))
+		_, err := execute(st, string(raw), "verify", "--key", another, "--request", "-")
+		require.ErrorIs(st, err, webhook.ErrSignatureMismatch)
+	})
+
+	t.Run("KO - tolerance too small error", func(st *testing.T) {
+		for _, tolerance := range []string{"30s", "-1m"} {
+			_, err := execute(st, string(raw), "verify", "--key", key, "--request", "-", "--tolerance", tolerance)
+			require.ErrorIs(st, err, webhook.ErrTimestampToleranceDurationTooSmall, tolerance)
+		}
+	})
+}
+
+func TestSend(t *testing.T) {
+	wh, err := webhook.New([]string{key})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(webhook.Middleware(wh)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "testing", r.Header.Get("X-Source"))
+		w.Write([]byte("ok"))
+	})))
+	defer server.Close()
+
+	var received string
+	capture := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(webhook.HeaderTimestamp)
+	}))
+	defer capture.Close()
+
+	t.Run("OK", func(st *testing.T) {
+		out, err := execute(st, "", "send", "--key", key, "--uri", server.URL, "--body", "-", "-H", "X-Source: testing")
+		require.NoError(st, err)
+		require.Contains(st, out, "200 OK")
+	})
+
+	t.Run("OK - timestamp", func(st *testing.T) {
+		ts := strconv.FormatInt(time.Now().Add(-time.Hour).UnixMilli(), 10)
+		_, err := execute(st, "", "send", "--key", key, "--uri", capture.URL, "--body", "-", "--timestamp", ts)
+		require.NoError(st, err)
+		require.Equal(st, ts, received)
+
+		// the receiver rejects the message because it is too old, it is signed with the given timestamp indeed
+		_, err = execute(st, "", "send", "--key", key, "--uri", server.URL, "--body", "-", "-H", "X-Source: testing", "--timestamp", ts)
+		require.ErrorContains(st, err, "Unauthorized")
+	})
+
+	t.Run("KO - endpoint error", func(st *testing.T) {
+		another := idx.Build(webhook.DefaultKeyNs, utils.RandomString(128))
+		_, err := execute(st, "", "send", "--key", another, "--uri", server.URL, "--body", "-", "-H", "X-Source: testing")
+		require.ErrorContains(st, err, "Unauthorized")
+	})
+}
+
+var (
+	key     = idx.Build(webhook.DefaultKeyNs, utils.RandomString(128))
+	payload = `{"say":"hello"}`
+)
+
+func execute(t *testing.T, stdin string, args ...string) (string, error) {
+	if stdin == "" {
+		stdin = payload
+	}
+
+	var out bytes.Buffer
+	command := New()
+	command.SetArgs(args)
+	command.SetIn(strings.NewReader(stdin))
+	command.SetOut(&out)
+	command.SetErr(&out)
+	command.SilenceUsage = true
+
+	err := command.Execute()
+	return out.String(), err
+}
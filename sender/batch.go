@@ -0,0 +1,189 @@
+package sender
+
+import (
+	"context"
+
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/sourcegraph/conc"
+)
+
+var BatchConcurrencyDefault = 10
+
+// Result is the outcome of a request of a batch, Index is the position of the request in the input
+type Result struct {
+	Index    int
+	Request  *entities.Request
+	Response *entities.Response
+	Error    error
+}
+
+type BatchOptions struct {
+	Concurrency     int
+	HostConcurrency int
+	HostKey         func(r *entities.Request) string
+}
+
+type BatchOption func(option *BatchOptions)
+
+// BatchConcurrency is the maximum number of requests we send at the same time, the default value is BatchConcurrencyDefault.
+// Values less than 1 are treated as 1
+func BatchConcurrency(n int) BatchOption {
+	return func(option *BatchOptions) {
+		option.Concurrency = n
+	}
+}
+
+// BatchHostConcurrency is the maximum number of requests we send to the same host at the same time, zero means no limit
+func BatchHostConcurrency(n int) BatchOption {
+	return func(option *BatchOptions) {
+		option.HostConcurrency = n
+	}
+}
+
+// BatchHostKey groups requests by the returned key for BatchHostConcurrency, the default key is the host of the request
+func BatchHostKey(fn func(r *entities.Request) string) BatchOption {
+	return func(option *BatchOptions) {
+		option.HostKey = fn
+	}
+}
+
+// Batch sends the requests concurrently and returns their results in the same order.
+// Requests that are not sent yet when the context is cancelled get the error of the context
+func Batch(ctx context.Context, send Send, requests []*entities.Request, withOptions ...BatchOption) []Result {
+	input := make(chan *entities.Request)
+	go func() {
+		defer close(input)
+		for i := range requests {
+			input <- requests[i]
+		}
+	}()
+
+	results := make([]Result, len(requests))
+	// we always drain the stream, so results are never dropped even when the context is cancelled
+	for result := range stream(ctx, send, input, nil, withOptions...) {
+		results[result.Index] = result
+	}
+	return results
+}
+
+// BatchStream sends the requests of the channel concurrently and streams their results back as soon as they are done.
+// The returned channel is closed after the requests channel is closed and all of its requests are done.
+// Requests that are not sent yet when the context is cancelled get the error of the context.
+// Requests of a host that is at its BatchHostConcurrency wait in the queue of the host without holding a slot of BatchConcurrency,
+// so a slow host never blocks requests of other hosts.
+// Callers must read the results until the channel is closed or cancel the context, results are dropped after the context is cancelled
+// so the sending goroutines never block on callers that stopped reading
+func BatchStream(ctx context.Context, send Send, requests <-chan *entities.Request, withOptions ...BatchOption) <-chan Result {
+	return stream(ctx, send, requests, ctx.Done(), withOptions...)
+}
+
+// stream stops delivering results after the abandoned channel is closed, a nil channel makes it deliver every result
+func stream(ctx context.Context, send Send, requests <-chan *entities.Request, abandoned <-chan struct{}, withOptions ...BatchOption) <-chan Result {
+	options := &BatchOptions{Concurrency: BatchConcurrencyDefault, HostKey: CircuitBreakerHostKey}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+
+	results := make(chan Result)
+	emit := func(result Result) {
+		select {
+		case results <- result:
+		case <-abandoned:
+		}
+	}
+
+	go func() {
+		defer close(results)
+
+		wg := conc.NewWaitGroup()
+		defer wg.Wait()
+
+		s := &scheduler{options: options, active: make(map[string]int), done: make(chan string)}
+		input := requests
+		cancelled := ctx.Done()
+		var index int
+		for input != nil || len(s.pending) > 0 || s.running > 0 {
+			// requests that are not sent yet will never be sent after the context is cancelled
+			if err := ctx.Err(); err != nil {
+				for _, task := range s.pending {
+					task.result.Error = err
+					emit(task.result)
+				}
+				s.pending = nil
+			}
+
+			for task, ok := s.next(); ok; task, ok = s.next() {
+				task := task
+				wg.Go(func() {
+					task.result.Response, task.result.Error = send(ctx, task.result.Request)
+					emit(task.result)
+					s.done <- task.key
+				})
+			}
+
+			// only take more requests when there is a free slot, so we never queue more requests than we need
+			var next <-chan *entities.Request
+			if s.running < options.Concurrency {
+				next = input
+			}
+
+			select {
+			case r, ok := <-next:
+				if !ok {
+					input = nil
+					continue
+				}
+				task := &batchtask{result: Result{Index: index, Request: r}}
+				index++
+				if options.HostConcurrency > 0 {
+					task.key = options.HostKey(r)
+				}
+				s.pending = append(s.pending, task)
+			case key := <-s.done:
+				s.running--
+				s.active[key]--
+			case <-cancelled:
+				// the context is checked at the beginning of the loop, stop listening to it to not spin
+				cancelled = nil
+			}
+		}
+	}()
+
+	return results
+}
+
+type batchtask struct {
+	key    string
+	result Result
+}
+
+// scheduler is owned by the loop of BatchStream, it keeps the queue of requests that are waiting for their host
+type scheduler struct {
+	options *BatchOptions
+	pending []*batchtask
+	running int
+	active  map[string]int
+	done    chan string
+}
+
+// next takes the first pending request whose host has a free slot when there is a free slot in total
+func (s *scheduler) next() (*batchtask, bool) {
+	if s.running >= s.options.Concurrency {
+		return nil, false
+	}
+
+	for i, task := range s.pending {
+		if s.options.HostConcurrency > 0 && s.active[task.key] >= s.options.HostConcurrency {
+			continue
+		}
+
+		s.pending = append(s.pending[:i], s.pending[i+1:]...)
+		s.running++
+		s.active[task.key]++
+		return task, true
+	}
+	return nil, false
+}
@@ -0,0 +1,208 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		send, err := New(testconf, testify.Logger())
+		require.NoError(st, err)
+
+		requests := make([]*entities.Request, 20)
+		for i := range requests {
+			requests[i] = &entities.Request{
+				Method: http.MethodPost,
+				Uri:    fmt.Sprintf("%s/delay?ms=%d", server.URL, testdata.Fake.IntBetween(1, 50)),
+				Body:   []byte(fmt.Sprintf(`{"index":%d}`, i)),
+			}
+		}
+
+		results := Batch(context.Background(), send, requests, BatchConcurrency(5))
+		require.Len(st, results, len(requests))
+		for i := range results {
+			require.Equal(st, i, results[i].Index)
+			require.Equal(st, requests[i], results[i].Request)
+			require.NoError(st, results[i].Error)
+			require.Equal(st, http.StatusOK, results[i].Response.Status)
+			require.Contains(st, string(results[i].Response.Body), fmt.Sprintf(`{\"index\":%d}`, i))
+		}
+	})
+
+	t.Run("OK - concurrency", func(st *testing.T) {
+		counter := newinflight()
+		requests := testrequests(20, "http://example.com")
+
+		results := Batch(context.Background(), counter.send, requests, BatchConcurrency(3))
+		require.Len(st, results, len(requests))
+		require.Equal(st, 3, counter.max["example.com"])
+	})
+
+	t.Run("OK - host concurrency", func(st *testing.T) {
+		counter := newinflight()
+		requests := append(testrequests(10, "http://one.example.com"), testrequests(10, "http://two.example.com")...)
+
+		results := Batch(context.Background(), counter.send, requests, BatchConcurrency(10), BatchHostConcurrency(2))
+		require.Len(st, results, len(requests))
+		require.Equal(st, 2, counter.max["one.example.com"])
+		require.Equal(st, 2, counter.max["two.example.com"])
+	})
+
+	t.Run("OK - slow host does not block other hosts", func(st *testing.T) {
+		gate := make(chan struct{})
+		send := func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			if CircuitBreakerHostKey(r) == "slow.example.com" {
+				<-gate
+			}
+			return &entities.Response{Status: http.StatusOK, Uri: r.Uri}, nil
+		}
+
+		requests := make(chan *entities.Request)
+		go func() {
+			defer close(requests)
+			for _, r := range append(testrequests(5, "http://slow.example.com"), testrequests(3, "http://fast.example.com")...) {
+				requests <- r
+			}
+		}()
+
+		stream := BatchStream(context.Background(), send, requests, BatchConcurrency(2), BatchHostConcurrency(1))
+		for i := 0; i < 3; i++ {
+			select {
+			case result := <-stream:
+				require.NoError(st, result.Error)
+				require.Equal(st, "fast.example.com", CircuitBreakerHostKey(result.Request))
+			case <-time.After(time.Second):
+				require.FailNow(st, "requests of the fast host are blocked by the slow host")
+			}
+		}
+
+		close(gate)
+		var count int
+		for result := range stream {
+			require.NoError(st, result.Error)
+			count++
+		}
+		require.Equal(st, 5, count)
+	})
+
+	t.Run("KO - context cancelled", func(st *testing.T) {
+		counter := newinflight()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := Batch(ctx, counter.send, testrequests(5, "http://example.com"))
+		for i := range results {
+			require.ErrorIs(st, results[i].Error, context.Canceled)
+			require.Nil(st, results[i].Response)
+		}
+		require.Empty(st, counter.max)
+	})
+
+	t.Run("KO - context cancelled while waiting for the host", func(st *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		send := func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			cancel()
+			return &entities.Response{Status: http.StatusOK, Uri: r.Uri}, nil
+		}
+
+		results := Batch(ctx, send, testrequests(5, "http://example.com"), BatchHostConcurrency(1))
+		require.NoError(st, results[0].Error)
+		for i := 1; i < len(results); i++ {
+			require.ErrorIs(st, results[i].Error, context.Canceled)
+		}
+	})
+}
+
+func TestBatchStream(t *testing.T) {
+	counter := newinflight()
+	requests := make(chan *entities.Request)
+	go func() {
+		defer close(requests)
+		for _, r := range testrequests(10, "http://example.com") {
+			requests <- r
+		}
+	}()
+
+	seen := map[int]bool{}
+	for result := range BatchStream(context.Background(), counter.send, requests, BatchConcurrency(4)) {
+		require.NoError(t, result.Error)
+		require.Equal(t, http.StatusOK, result.Response.Status)
+		seen[result.Index] = true
+	}
+	require.Len(t, seen, 10)
+}
+
+func TestBatchStream_Abandoned(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := make(chan *entities.Request)
+	fed := make(chan struct{})
+	go func() {
+		defer close(fed)
+		defer close(requests)
+		for _, r := range testrequests(10, "http://example.com") {
+			requests <- r
+		}
+	}()
+
+	counter := newinflight()
+	stream := BatchStream(ctx, counter.send, requests, BatchConcurrency(1))
+	<-stream
+	// stop reading, the remaining requests must still be consumed instead of blocking forever
+	cancel()
+
+	select {
+	case <-fed:
+	case <-time.After(time.Second):
+		require.FailNow(t, "the stream is blocked by the caller that stopped reading")
+	}
+}
+
+func testrequests(count int, uri string) []*entities.Request {
+	requests := make([]*entities.Request, count)
+	for i := range requests {
+		requests[i] = &entities.Request{Method: http.MethodGet, Uri: uri}
+	}
+	return requests
+}
+
+// inflight records the maximum number of requests per host that are sent at the same time
+type inflight struct {
+	mu      sync.Mutex
+	current map[string]int
+	max     map[string]int
+}
+
+func newinflight() *inflight {
+	return &inflight{current: map[string]int{}, max: map[string]int{}}
+}
+
+func (c *inflight) send(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+	host := CircuitBreakerHostKey(r)
+
+	c.mu.Lock()
+	c.current[host]++
+	c.max[host] = max(c.max[host], c.current[host])
+	c.mu.Unlock()
+
+	time.Sleep(time.Millisecond * 20)
+
+	c.mu.Lock()
+	c.current[host]--
+	c.mu.Unlock()
+	return &entities.Response{Status: http.StatusOK, Uri: r.Uri}, nil
+}
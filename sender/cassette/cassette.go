@@ -0,0 +1,214 @@
+package cassette
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kanthorlabs/common/sender"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+// Sentinels are errors that callers branch on with errors.Is, they are recorded by their code and matched again on replay
+var Sentinels = []error{
+	sender.ErrCircuitOpen,
+	sender.ErrEgressDenied,
+	sender.ErrUnixTargetDenied,
+	sender.ErrTooManyRedirects,
+	sender.ErrBodyTooLarge,
+	sender.ErrTlsMalformed,
+	context.Canceled,
+	context.DeadlineExceeded,
+}
+
+// Cassette is the list of request and response pairs that are recorded by the Recorder and served by the Replayer
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request   `json:"request"`
+	Response *Response `json:"response,omitempty"`
+	// Error is the error of the sender, the response is still recorded when the sender returns it along with the error
+	Error string `json:"error,omitempty"`
+	// Code is the first of Sentinels the error matches, so the replayed error matches it as well
+	Code string `json:"code,omitempty"`
+}
+
+type Request struct {
+	Method  string      `json:"method"`
+	Uri     string      `json:"uri"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+	// BodyHash is the SHA-256 of the body before it is redacted, so we could match bodies that contain secrets
+	BodyHash string `json:"body_hash"`
+}
+
+type Response struct {
+	Status         int         `json:"status"`
+	Uri            string      `json:"uri"`
+	Headers        http.Header `json:"headers"`
+	Body           string      `json:"body"`
+	Truncated      bool        `json:"truncated,omitempty"`
+	TransportError string      `json:"transport_error,omitempty"`
+}
+
+// Load reads the cassette from the file
+func Load(file string) (*Cassette, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCassetteMalformed, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to the file, the file is overwritten if it exists
+func (cassette *Cassette) Save(file string) error {
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+type Options struct {
+	Matchers        []Matcher
+	RedactedHeaders []string
+	Redactor        sender.Redactor
+}
+
+type Option func(option *Options)
+
+// Matchers replaces the default matchers which are MatchMethod and MatchUri
+func Matchers(matchers ...Matcher) Option {
+	return func(option *Options) {
+		option.Matchers = matchers
+	}
+}
+
+// RedactHeaders are headers whose values are replaced before they are saved, the default ones are sender.RedactedHeaders
+func RedactHeaders(names ...string) Option {
+	return func(option *Options) {
+		option.RedactedHeaders = append(option.RedactedHeaders, names...)
+	}
+}
+
+// RedactBody hides secrets of request and response bodies before they are saved, sender.RedactJson is a good start
+func RedactBody(redactor sender.Redactor) Option {
+	return func(option *Options) {
+		option.Redactor = redactor
+	}
+}
+
+func options(withOptions []Option) *Options {
+	options := &Options{
+		Matchers:        []Matcher{MatchMethod(), MatchUri()},
+		RedactedHeaders: slices.Clone(sender.RedactedHeaders),
+	}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+	return options
+}
+
+// Matcher tells whether the request matches the recorded one
+type Matcher func(r *entities.Request, body []byte, recorded *Request) bool
+
+func MatchMethod() Matcher {
+	return func(r *entities.Request, body []byte, recorded *Request) bool {
+		return strings.EqualFold(r.Method, recorded.Method)
+	}
+}
+
+// MatchUri compares uris after they are redacted the same way the Recorder does
+func MatchUri() Matcher {
+	return func(r *entities.Request, body []byte, recorded *Request) bool {
+		return r.Uri == recorded.Uri || sender.RedactUri(r.Uri) == recorded.Uri
+	}
+}
+
+// MatchBody compares the hash of bodies so it works with redacted bodies as well
+func MatchBody() Matcher {
+	return func(r *entities.Request, body []byte, recorded *Request) bool {
+		return hash(body) == recorded.BodyHash
+	}
+}
+
+// MatchHeaders compares values of the given headers, redacted headers could not be matched
+func MatchHeaders(names ...string) Matcher {
+	return func(r *entities.Request, body []byte, recorded *Request) bool {
+		for _, name := range names {
+			if !slices.Equal(r.Headers.Values(name), recorded.Headers.Values(name)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func hash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func redact(options *Options, headers http.Header, body []byte) (http.Header, string) {
+	redacted := headers.Clone()
+	if redacted == nil {
+		redacted = make(http.Header)
+	}
+	for name := range redacted {
+		if slices.ContainsFunc(options.RedactedHeaders, func(h string) bool { return strings.EqualFold(h, name) }) {
+			redacted[name] = []string{sender.Redacted}
+		}
+	}
+
+	if options.Redactor != nil {
+		body = options.Redactor(body)
+	}
+	return redacted, string(body)
+}
+
+func code(err error) string {
+	for _, sentinel := range Sentinels {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	return ""
+}
+
+// replayed is the recorded error that still matches its sentinel
+type replayed struct {
+	message  string
+	sentinel error
+}
+
+func (err *replayed) Error() string {
+	return err.message
+}
+
+func (err *replayed) Unwrap() error {
+	return err.sentinel
+}
+
+func replayerror(interaction *Interaction) error {
+	err := &replayed{message: interaction.Error}
+	for _, sentinel := range Sentinels {
+		if sentinel.Error() == interaction.Code {
+			err.sentinel = sentinel
+		}
+	}
+	return err
+}
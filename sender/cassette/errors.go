@@ -0,0 +1,9 @@
+package cassette
+
+import "errors"
+
+var (
+	// ErrInteractionNotFound is returned by the replayer when no recorded interaction matches the request
+	ErrInteractionNotFound = errors.New("SENDER.CASSETTE.INTERACTION.NOT_FOUND.ERROR")
+	ErrCassetteMalformed   = errors.New("SENDER.CASSETTE.MALFORMED.ERROR")
+)
@@ -0,0 +1,98 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/kanthorlabs/common/sender"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+// NewRecorder wraps the sender to record every request and its response, call Save to write them into a file
+func NewRecorder(send sender.Send, withOptions ...Option) *Recorder {
+	return &Recorder{send: send, options: options(withOptions), cassette: &Cassette{}}
+}
+
+type Recorder struct {
+	send     sender.Send
+	options  *Options
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// Send sends the request with the wrapped sender and records it, credentials in the query of uris are redacted.
+// Streamed bodies are read into memory so they could be recorded, the caller still gets them as streams
+func (recorder *Recorder) Send(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+	req := *r
+	body := r.Body
+	if r.Reader != nil {
+		data, err := io.ReadAll(r.Reader)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+		req.Reader = bytes.NewReader(data)
+	}
+
+	headers, redacted := redact(recorder.options, r.Headers, body)
+	interaction := Interaction{
+		Request: Request{Method: r.Method, Uri: sender.RedactUri(r.Uri), Headers: headers, Body: redacted, BodyHash: hash(body)},
+	}
+
+	res, err := recorder.send(ctx, &req)
+	if err != nil && res == nil {
+		interaction.Error, interaction.Code = err.Error(), code(err)
+		recorder.record(interaction)
+		return nil, err
+	}
+
+	resbody := res.Body
+	if res.Stream != nil {
+		data, rerr := io.ReadAll(res.Stream)
+		res.Stream.Close()
+		if rerr != nil {
+			return nil, rerr
+		}
+		resbody = data
+		res.Stream = io.NopCloser(bytes.NewReader(data))
+	}
+
+	headers, redacted = redact(recorder.options, res.Headers, resbody)
+	interaction.Response = &Response{
+		Status:         res.Status,
+		Uri:            sender.RedactUri(res.Uri),
+		Headers:        headers,
+		Body:           redacted,
+		Truncated:      res.Truncated,
+		TransportError: res.TransportError,
+	}
+	// some senders return the response along with the error, for example when the circuit is open
+	if err != nil {
+		interaction.Error, interaction.Code = err.Error(), code(err)
+	}
+	recorder.record(interaction)
+	return res, err
+}
+
+// Cassette returns a copy of the recorded interactions
+func (recorder *Recorder) Cassette() *Cassette {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	interactions := make([]Interaction, len(recorder.cassette.Interactions))
+	copy(interactions, recorder.cassette.Interactions)
+	return &Cassette{Interactions: interactions}
+}
+
+// Save writes the recorded interactions into the file
+func (recorder *Recorder) Save(file string) error {
+	return recorder.Cassette().Save(file)
+}
+
+func (recorder *Recorder) record(interaction Interaction) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.cassette.Interactions = append(recorder.cassette.Interactions, interaction)
+}
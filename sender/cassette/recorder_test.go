@@ -0,0 +1,140 @@
+package cassette
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/kanthorlabs/common/sender"
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	server := echoserver()
+	defer server.Close()
+
+	send, err := sender.New(config.Default, testify.Logger())
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		recorder := NewRecorder(send, RedactBody(sender.RedactJson("password")))
+
+		req := &entities.Request{
+			Method:  http.MethodPost,
+			Uri:     server.URL + "/login?token=secret",
+			Headers: http.Header{"Authorization": []string{"Bearer secret"}},
+			Body:    []byte(`{"username":"kanthor","password":"secret"}`),
+		}
+		res, err := recorder.Send(context.Background(), req)
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+		// the caller always gets the real response
+		require.Contains(st, string(res.Body), "secret")
+
+		file := path.Join(st.TempDir(), "cassette.json")
+		require.NoError(st, recorder.Save(file))
+
+		data, err := os.ReadFile(file)
+		require.NoError(st, err)
+		require.NotContains(st, string(data), "secret")
+
+		cassette, err := Load(file)
+		require.NoError(st, err)
+		require.Len(st, cassette.Interactions, 1)
+		require.Equal(st, hash(req.Body), cassette.Interactions[0].Request.BodyHash)
+		require.Equal(st, sender.Redacted, cassette.Interactions[0].Request.Headers.Get("Authorization"))
+		require.Equal(st, http.StatusOK, cassette.Interactions[0].Response.Status)
+		require.Equal(st, server.URL+"/login?token="+sender.Redacted, cassette.Interactions[0].Request.Uri)
+
+		replayed, err := NewReplayer(cassette).Send(context.Background(), req)
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, replayed.Status)
+	})
+
+	t.Run("OK - streams", func(st *testing.T) {
+		recorder := NewRecorder(send)
+
+		payload := testdata.Fake.Lorem().Sentence(10)
+		req := &entities.Request{Method: http.MethodPut, Uri: server.URL + "/upload", Reader: strings.NewReader(payload), Stream: true}
+		res, err := recorder.Send(context.Background(), req)
+		require.NoError(st, err)
+
+		data, err := io.ReadAll(res.Stream)
+		require.NoError(st, err)
+		require.Equal(st, payload, string(data))
+
+		cassette := recorder.Cassette()
+		require.Equal(st, payload, cassette.Interactions[0].Request.Body)
+		require.Equal(st, payload, cassette.Interactions[0].Response.Body)
+	})
+
+	t.Run("OK - error", func(st *testing.T) {
+		recorder := NewRecorder(func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			return nil, testdata.ErrGeneric
+		})
+
+		_, err := recorder.Send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL})
+		require.ErrorIs(st, err, testdata.ErrGeneric)
+		require.Equal(st, testdata.ErrGeneric.Error(), recorder.Cassette().Interactions[0].Error)
+	})
+
+	t.Run("OK - error with response", func(st *testing.T) {
+		recorder := NewRecorder(func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			return &entities.Response{Status: entities.StatusCircuitOpen, Uri: r.Uri, Body: []byte(sender.ErrCircuitOpen.Error())}, sender.ErrCircuitOpen
+		})
+
+		req := &entities.Request{Method: http.MethodGet, Uri: server.URL}
+		res, err := recorder.Send(context.Background(), req)
+		require.ErrorIs(st, err, sender.ErrCircuitOpen)
+		require.Equal(st, entities.StatusCircuitOpen, res.Status)
+
+		cassette := recorder.Cassette()
+		require.Equal(st, sender.ErrCircuitOpen.Error(), cassette.Interactions[0].Error)
+		require.Equal(st, entities.StatusCircuitOpen, cassette.Interactions[0].Response.Status)
+
+		require.Equal(st, sender.ErrCircuitOpen.Error(), cassette.Interactions[0].Code)
+
+		replayed, err := NewReplayer(cassette).Send(context.Background(), req)
+		require.ErrorIs(st, err, sender.ErrCircuitOpen)
+		require.Equal(st, entities.StatusCircuitOpen, replayed.Status)
+	})
+
+	t.Run("OK - sentinel error", func(st *testing.T) {
+		denied := fmt.Errorf("%w: 127.0.0.1:80", sender.ErrEgressDenied)
+		recorder := NewRecorder(func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			return nil, denied
+		})
+
+		req := &entities.Request{Method: http.MethodGet, Uri: server.URL}
+		_, err := recorder.Send(context.Background(), req)
+		require.ErrorIs(st, err, sender.ErrEgressDenied)
+
+		file := path.Join(st.TempDir(), "cassette.json")
+		require.NoError(st, recorder.Save(file))
+		send, err := Replay(file)
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), req)
+		require.Nil(st, res)
+		require.ErrorIs(st, err, sender.ErrEgressDenied)
+		require.NotErrorIs(st, err, sender.ErrCircuitOpen)
+		require.EqualError(st, err, denied.Error())
+	})
+}
+
+func echoserver() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		io.Copy(w, r.Body)
+	}))
+}
@@ -0,0 +1,119 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/kanthorlabs/common/sender"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+// Replay loads the cassette from the file and returns a sender that serves its interactions offline
+func Replay(file string, withOptions ...Option) (sender.Send, error) {
+	cassette, err := Load(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(cassette, withOptions...).Send, nil
+}
+
+// NewReplayer serves the interactions of the cassette, every interaction is served once in the recorded order
+func NewReplayer(cassette *Cassette, withOptions ...Option) *Replayer {
+	return &Replayer{
+		cassette: cassette,
+		options:  options(withOptions),
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+type Replayer struct {
+	cassette *Cassette
+	options  *Options
+	mu       sync.Mutex
+	used     []bool
+}
+
+// Send returns the response of the first unused interaction that matches the request
+// or ErrInteractionNotFound if there is no such interaction
+func (replayer *Replayer) Send(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	body := r.Body
+	if r.Reader != nil {
+		data, err := io.ReadAll(r.Reader)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	interaction, err := replayer.match(r, body)
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Response == nil {
+		return nil, replayerror(interaction)
+	}
+
+	res := &entities.Response{
+		Status:         interaction.Response.Status,
+		Uri:            interaction.Response.Uri,
+		Headers:        interaction.Response.Headers.Clone(),
+		Body:           []byte(interaction.Response.Body),
+		Truncated:      interaction.Response.Truncated,
+		TransportError: interaction.Response.TransportError,
+	}
+	if r.Stream {
+		res.Stream = io.NopCloser(bytes.NewReader(res.Body))
+		res.Body = []byte{}
+	}
+	if interaction.Error != "" {
+		return res, replayerror(interaction)
+	}
+	return res, nil
+}
+
+// Unused returns the interactions that have not been served yet, it is handy to make sure a test sends every expected request
+func (replayer *Replayer) Unused() []Interaction {
+	replayer.mu.Lock()
+	defer replayer.mu.Unlock()
+
+	var interactions []Interaction
+	for i := range replayer.cassette.Interactions {
+		if !replayer.used[i] {
+			interactions = append(interactions, replayer.cassette.Interactions[i])
+		}
+	}
+	return interactions
+}
+
+func (replayer *Replayer) match(r *entities.Request, body []byte) (*Interaction, error) {
+	replayer.mu.Lock()
+	defer replayer.mu.Unlock()
+
+	for i := range replayer.cassette.Interactions {
+		if replayer.used[i] {
+			continue
+		}
+		if matches(replayer.options.Matchers, r, body, &replayer.cassette.Interactions[i].Request) {
+			replayer.used[i] = true
+			return &replayer.cassette.Interactions[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, r.Method, sender.RedactUri(r.Uri))
+}
+
+func matches(matchers []Matcher, r *entities.Request, body []byte, recorded *Request) bool {
+	for _, matcher := range matchers {
+		if !matcher(r, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	cassette := &Cassette{
+		Interactions: []Interaction{
+			{
+				Request:  Request{Method: http.MethodGet, Uri: "https://example.com/1", Headers: http.Header{"X-Tenant": []string{"one"}}, BodyHash: hash(nil)},
+				Response: &Response{Status: http.StatusOK, Uri: "https://example.com/1", Body: "first"},
+			},
+			{
+				Request:  Request{Method: http.MethodGet, Uri: "https://example.com/1", Headers: http.Header{"X-Tenant": []string{"two"}}, BodyHash: hash(nil)},
+				Response: &Response{Status: http.StatusAccepted, Uri: "https://example.com/1", Body: "second"},
+			},
+			{
+				Request:  Request{Method: http.MethodPost, Uri: "https://example.com/2", BodyHash: hash([]byte(`{"id":1}`))},
+				Response: &Response{Status: http.StatusCreated, Body: "created"},
+			},
+			{
+				Request: Request{Method: http.MethodDelete, Uri: "https://example.com/3"},
+				Error:   testdata.ErrGeneric.Error(),
+			},
+		},
+	}
+	file := path.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, cassette.Save(file))
+
+	t.Run("OK", func(st *testing.T) {
+		send, err := Replay(file)
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: "https://example.com/1"})
+		require.NoError(st, err)
+		require.Equal(st, "first", string(res.Body))
+
+		// interactions are served in the recorded order
+		res, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: "https://example.com/1"})
+		require.NoError(st, err)
+		require.Equal(st, "second", string(res.Body))
+
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodDelete, Uri: "https://example.com/3"})
+		require.EqualError(st, err, testdata.ErrGeneric.Error())
+	})
+
+	t.Run("OK - headers", func(st *testing.T) {
+		send, err := Replay(file, Matchers(MatchMethod(), MatchUri(), MatchHeaders("X-Tenant")))
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: "https://example.com/1", Headers: http.Header{"X-Tenant": []string{"two"}}})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusAccepted, res.Status)
+	})
+
+	t.Run("OK - body", func(st *testing.T) {
+		replayer := NewReplayer(cassette, Matchers(MatchMethod(), MatchUri(), MatchBody()))
+
+		_, err := replayer.Send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: "https://example.com/2", Body: []byte(`{"id":2}`)})
+		require.ErrorIs(st, err, ErrInteractionNotFound)
+
+		res, err := replayer.Send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: "https://example.com/2", Body: []byte(`{"id":1}`), Stream: true})
+		require.NoError(st, err)
+		data, err := io.ReadAll(res.Stream)
+		require.NoError(st, err)
+		require.Equal(st, "created", string(data))
+
+		require.Len(st, replayer.Unused(), 3)
+	})
+
+	t.Run("KO - not found", func(st *testing.T) {
+		send, err := Replay(file)
+		require.NoError(st, err)
+
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: "https://example.com/404"})
+		require.ErrorIs(st, err, ErrInteractionNotFound)
+		require.ErrorContains(st, err, "GET https://example.com/404")
+
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: "https://example.com/404?token=secret"})
+		require.ErrorIs(st, err, ErrInteractionNotFound)
+		require.NotContains(st, err.Error(), "secret")
+	})
+
+	t.Run("KO - cassette malformed", func(st *testing.T) {
+		malformed := path.Join(st.TempDir(), "cassette.json")
+		require.NoError(st, os.WriteFile(malformed, []byte("{"), 0644))
+
+		_, err := Replay(malformed)
+		require.ErrorIs(st, err, ErrCassetteMalformed)
+	})
+}
@@ -0,0 +1,74 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/kanthorlabs/common/circuitbreaker"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+// breaker fails fast when the circuit of the request is open.
+// Transport errors, 5xx and 429 responses are counted as failures of the circuit,
+// except requests that are canceled by the caller because they tell nothing about the health of the host
+func breaker(send Send, cb circuitbreaker.CircuitBreaker, key func(r *entities.Request) string) Send {
+	if key == nil {
+		key = CircuitBreakerHostKey
+	}
+
+	return func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+		// invalid requests must not affect the circuit
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+
+		var res *entities.Response
+		_, err := cb.Do(key(r), func() (any, error) {
+			var err error
+			if res, err = send(ctx, r); err != nil {
+				return nil, err
+			}
+			if unhealthy(res) && !errors.Is(ctx.Err(), context.Canceled) {
+				return res, errUnhealthy
+			}
+			return res, nil
+		}, func(err error) error {
+			if errors.Is(err, errUnhealthy) {
+				return err
+			}
+			return nil
+		})
+
+		if errors.Is(err, circuitbreaker.ErrOpenState) || errors.Is(err, circuitbreaker.ErrTooManyRequests) {
+			return &entities.Response{
+				Status:  entities.StatusCircuitOpen,
+				Headers: make(http.Header),
+				Uri:     r.Uri,
+				Body:    []byte(ErrCircuitOpen.Error()),
+			}, errors.Join(ErrCircuitOpen, err)
+		}
+		if err != nil && !errors.Is(err, errUnhealthy) {
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// CircuitBreakerHostKey is the default key of circuits, it is the host and the port of the request
+// or the socket path of unix domain socket requests
+func CircuitBreakerHostKey(r *entities.Request) string {
+	uri, err := url.Parse(r.Uri)
+	if err != nil {
+		return r.Uri
+	}
+	if socket, _, ok := UnixSocket(uri); ok {
+		return socket
+	}
+	return uri.Host
+}
+
+func unhealthy(res *entities.Response) bool {
+	return res.Status < 0 || res.Status == http.StatusTooManyRequests || res.Status >= http.StatusInternalServerError
+}
@@ -0,0 +1,126 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cbconfig "github.com/kanthorlabs/common/circuitbreaker/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	conf := *testconf
+	conf.Retry.Count = 0
+	conf.CircuitBreaker = testcbconf
+
+	t.Run("OK", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+		another := httpserver()
+		defer another.Close()
+
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		opened := trip(st, send, server.URL+"/500")
+		require.Equal(st, entities.StatusCircuitOpen, opened.Status)
+		require.Equal(st, ErrCircuitOpen.Error(), opened.StatusText())
+		require.False(st, opened.Ok())
+
+		// other hosts are not affected
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: another.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+	})
+
+	t.Run("OK - custom key", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		send, err := New(&conf, testify.Logger(), CircuitBreakerKey(func(r *entities.Request) string {
+			return "all"
+		}))
+		require.NoError(st, err)
+
+		trip(st, send, server.URL+"/500")
+
+		another := httpserver()
+		defer another.Close()
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: another.URL + "/200"})
+		require.ErrorIs(st, err, ErrCircuitOpen)
+	})
+
+	t.Run("OK - invalid requests are not counted", func(st *testing.T) {
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		for i := 0; i < int(testcbconf.Open.Conditions.ErrorConsecutive)*2; i++ {
+			_, err := send(context.Background(), &entities.Request{Method: http.MethodConnect, Uri: "http://localhost"})
+			require.ErrorContains(st, err, "SENDER.REQUEST.METHOD")
+		}
+	})
+
+	t.Run("OK - canceled requests are not counted", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		for i := 0; i < int(testcbconf.Open.Conditions.ErrorConsecutive)*2; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			res, err := send(ctx, &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+			require.NoError(st, err)
+			require.Equal(st, entities.StatusTransportError, res.Status)
+		}
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+	})
+
+	t.Run("KO - configuration error", func(st *testing.T) {
+		conf := *testconf
+		conf.CircuitBreaker = &cbconfig.Config{}
+		_, err := New(&conf, testify.Logger())
+		require.ErrorContains(st, err, "CIRCUIT_BREAKER.CONFIG")
+	})
+}
+
+func TestCircuitBreakerHostKey(t *testing.T) {
+	require.Equal(t, "example.com:8080", CircuitBreakerHostKey(&entities.Request{Uri: "https://example.com:8080/path"}))
+	require.Equal(t, "example.com", CircuitBreakerHostKey(&entities.Request{Uri: "https://example.com/path?q=1"}))
+}
+
+var testcbconf = &cbconfig.Config{
+	Size:  5,
+	Close: cbconfig.Close{CleanupInterval: 5000},
+	Half:  cbconfig.Half{PassthroughRequests: 1},
+	Open: cbconfig.Open{
+		Duration: 5000,
+		Conditions: cbconfig.OpenConditions{
+			ErrorConsecutive: 3,
+			ErrorRatio:       0.5,
+		},
+	},
+}
+
+// trip sends failed requests until the circuit is open and returns the response of the open circuit
+func trip(t *testing.T, send Send, uri string) *entities.Response {
+	for i := 0; i < 10; i++ {
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: uri})
+		if err != nil {
+			require.ErrorIs(t, err, ErrCircuitOpen)
+			require.NotNil(t, res)
+			return res
+		}
+		require.Equal(t, http.StatusInternalServerError, res.Status)
+	}
+
+	require.FailNow(t, "the circuit is not open")
+	return nil
+}
@@ -0,0 +1,73 @@
+package sender
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+// Classify returns the transport error category of the error, see entities.Response.TransportError
+func Classify(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	// check the cancellation first because a canceled request could also look like a timeout or a refused connection
+	if errors.Is(err, context.Canceled) {
+		return entities.TransportErrorCanceled
+	}
+
+	var dnserr *net.DNSError
+	if errors.As(err, &dnserr) {
+		if dnserr.IsTimeout {
+			return entities.TransportErrorTimeout
+		}
+		return entities.TransportErrorDns
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return entities.TransportErrorConnectRefused
+	}
+
+	if tlserror(err) {
+		return entities.TransportErrorTls
+	}
+
+	if errors.Is(err, ErrTooManyRedirects) {
+		return entities.TransportErrorTooManyRedirects
+	}
+
+	var maxbytes *http.MaxBytesError
+	if errors.As(err, &maxbytes) || errors.Is(err, ErrBodyTooLarge) {
+		return entities.TransportErrorBodyTooLarge
+	}
+
+	var nerr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &nerr) && nerr.Timeout()) {
+		return entities.TransportErrorTimeout
+	}
+
+	return entities.TransportErrorUnknown
+}
+
+func tlserror(err error) bool {
+	var record tls.RecordHeaderError
+	var alert tls.AlertError
+	var verification *tls.CertificateVerificationError
+	var authority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+
+	return errors.As(err, &record) ||
+		errors.As(err, &alert) ||
+		errors.As(err, &verification) ||
+		errors.As(err, &authority) ||
+		errors.As(err, &hostname) ||
+		errors.As(err, &invalid)
+}
@@ -0,0 +1,89 @@
+package sender
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	testcases := map[string]error{
+		entities.TransportErrorCanceled:         fmt.Errorf("wrapped: %w", context.Canceled),
+		entities.TransportErrorDns:              &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true},
+		entities.TransportErrorConnectRefused:   &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED},
+		entities.TransportErrorTls:              fmt.Errorf("wrapped: %w", x509.UnknownAuthorityError{}),
+		entities.TransportErrorTooManyRedirects: fmt.Errorf("wrapped: %w", ErrTooManyRedirects),
+		entities.TransportErrorBodyTooLarge:     &http.MaxBytesError{Limit: 1},
+		entities.TransportErrorTimeout:          fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+		entities.TransportErrorUnknown:          testdata.ErrGeneric,
+	}
+
+	for expected, err := range testcases {
+		require.Equal(t, expected, Classify(err), err.Error())
+	}
+	require.Equal(t, entities.TransportErrorTimeout, Classify(&net.DNSError{Err: "timeout", IsTimeout: true}))
+	require.Equal(t, entities.TransportErrorTimeout, Classify(&timeouterror{}))
+	require.Empty(t, Classify(nil))
+}
+
+func TestClassify_Send(t *testing.T) {
+	conf := *testconf
+	conf.Retry.Count = 0
+	send, err := NewHttp(&conf, testify.Logger())
+	require.NoError(t, err)
+
+	get := func(ctx context.Context, uri string) *entities.Response {
+		res, err := send(ctx, &entities.Request{Method: http.MethodGet, Uri: uri})
+		require.NoError(t, err)
+		require.Equal(t, entities.StatusTransportError, res.Status)
+		require.NotEmpty(t, res.Body)
+		require.Equal(t, res.TransportError, res.Attempts[len(res.Attempts)-1].TransportError)
+		return res
+	}
+
+	t.Run("connect refused", func(st *testing.T) {
+		server := httpserver()
+		uri := server.URL + "/200"
+		server.Close()
+
+		require.True(st, get(context.Background(), uri).IsConnectRefused())
+	})
+
+	t.Run("tls", func(st *testing.T) {
+		server := httptest.NewTLSServer(http.NotFoundHandler())
+		defer server.Close()
+
+		require.True(st, get(context.Background(), server.URL).IsTlsError())
+	})
+
+	t.Run("too many redirects", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		require.True(st, get(context.Background(), server.URL+"/loop").IsTooManyRedirects())
+	})
+
+	t.Run("dns", func(st *testing.T) {
+		res := get(context.Background(), "http://kanthor.invalid/200")
+		require.True(st, res.IsDnsError() || res.IsTimeout(), res.TransportError)
+	})
+
+	t.Run("canceled", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.True(st, get(ctx, server.URL+"/200").IsCanceled())
+	})
+}
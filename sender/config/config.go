@@ -1,15 +1,52 @@
 package config
 
 import (
+	"errors"
+
+	cbconfig "github.com/kanthorlabs/common/circuitbreaker/config"
+	"github.com/kanthorlabs/common/configuration"
 	"github.com/kanthorlabs/common/validator"
 )
 
+func New(provider configuration.Provider) (*Config, error) {
+	var conf Wrapper
+	if err := provider.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &conf.Sender, nil
+}
+
+type Wrapper struct {
+	Sender Config `json:"sender" yaml:"sender" mapstructure:"sender"`
+}
+
+func (conf *Wrapper) Validate() error {
+	if err := conf.Sender.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+var (
+	// MaxBodySizeDefault is used when MaxBodySize is not set
+	MaxBodySizeDefault int64 = 10 * 1024 * 1024
+	// MaxBodySizeUnlimited must be set explicitly to read response bodies without any limit
+	MaxBodySizeUnlimited int64 = -1
+)
+
 var Default = &Config{
-	Timeout: 5000,
-	Headers: map[string]string{},
+	Timeout:     5000,
+	Headers:     map[string]string{},
+	MaxBodySize: MaxBodySizeDefault,
 	Retry: Retry{
-		Count:    1,
-		WaitTime: 500,
+		Count:       1,
+		WaitTime:    500,
+		MaxWaitTime: 10000,
+		Budget:      60000,
 	},
 }
 
@@ -17,11 +54,23 @@ type Config struct {
 	Timeout int64             `json:"timeout" yaml:"timeout" mapstructure:"timeout"`
 	Headers map[string]string `json:"header" yaml:"header" mapstructure:"header"`
 	Retry   Retry             `json:"retry" yaml:"retry" mapstructure:"retry"`
+	// MaxBodySize is the maximum number of bytes of a response body we read, the rest is dropped and the response is flagged as truncated.
+	// MaxBodySizeDefault is used when it is not set, use MaxBodySizeUnlimited to read bodies without any limit
+	MaxBodySize int64 `json:"max_body_size" yaml:"max_body_size" mapstructure:"max_body_size"`
+	// CircuitBreaker enables the circuit breaker that is keyed by the host of the request, it is disabled when it is not set
+	CircuitBreaker *cbconfig.Config `json:"circuit_breaker,omitempty" yaml:"circuit_breaker,omitempty" mapstructure:"circuit_breaker"`
+	// Egress enables the egress policy to protect us from SSRF, it is disabled when it is not set
+	Egress *Egress `json:"egress,omitempty" yaml:"egress,omitempty" mapstructure:"egress"`
+	// Tls customizes how we verify servers and authenticate ourselves to them, the system defaults are used when it is not set
+	Tls *Tls `json:"tls,omitempty" yaml:"tls,omitempty" mapstructure:"tls"`
+	// Proxy sends requests through a proxy, the proxy of environment variables is used when it is not set
+	Proxy *Proxy `json:"proxy,omitempty" yaml:"proxy,omitempty" mapstructure:"proxy"`
 }
 
 func (conf *Config) Validate() error {
 	err := validator.Validate(
 		validator.NumberGreaterThanOrEqual("SENDER.CONFIG.TIMEOUT", conf.Timeout, 1000),
+		validator.NumberGreaterThanOrEqual("SENDER.CONFIG.MAX_BODY_SIZE", conf.MaxBodySize, MaxBodySizeUnlimited),
 	)
 	if err != nil {
 		return err
@@ -31,5 +80,41 @@ func (conf *Config) Validate() error {
 		return err
 	}
 
+	if conf.CircuitBreaker != nil {
+		if err := conf.CircuitBreaker.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if conf.Egress != nil {
+		if err := conf.Egress.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if conf.Tls != nil {
+		if err := conf.Tls.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if conf.Proxy != nil {
+		if err := conf.Proxy.Validate(); err != nil {
+			return err
+		}
+		// the egress policy checks the address we dial, which is the proxy instead of the destination
+		if conf.Egress != nil {
+			return errors.New("SENDER.CONFIG.PROXY could not be used with SENDER.CONFIG.EGRESS")
+		}
+	}
+
 	return nil
 }
+
+// BodySizeLimit returns the maximum number of bytes of a response body we read, it is MaxBodySizeUnlimited when there is no limit
+func (conf *Config) BodySizeLimit() int64 {
+	if conf.MaxBodySize == 0 {
+		return MaxBodySizeDefault
+	}
+	return conf.MaxBodySize
+}
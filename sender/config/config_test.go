@@ -1,12 +1,56 @@
 package config
 
 import (
+	"os"
+	"path"
 	"testing"
 
+	"github.com/kanthorlabs/common/configuration"
 	"github.com/kanthorlabs/common/testdata"
 	"github.com/stretchr/testify/require"
 )
 
+func TestNew(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		dir := st.TempDir()
+		content := `
+sender:
+  timeout: 3000
+  retry:
+    count: 2
+    wait_time: 500
+  tls:
+    ca: /etc/ssl/partner.pem
+    min_version: "1.2"
+  proxy:
+    uri: socks5://proxy.local:1080
+    no_proxy:
+      - 10.0.0.0/8
+      - .svc.cluster.local
+`
+		require.NoError(st, os.WriteFile(path.Join(dir, configuration.FileName+"."+configuration.FileExt), []byte(content), 0644))
+
+		provider, err := configuration.NewFile(testdata.Fake.Lorem().Word(), []string{dir})
+		require.NoError(st, err)
+
+		conf, err := New(provider)
+		require.NoError(st, err)
+		require.Equal(st, int64(3000), conf.Timeout)
+		require.Equal(st, "/etc/ssl/partner.pem", conf.Tls.Ca)
+		require.Equal(st, "1.2", conf.Tls.MinVersion)
+		require.Equal(st, "socks5://proxy.local:1080", conf.Proxy.Uri)
+		require.Equal(st, []string{"10.0.0.0/8", ".svc.cluster.local"}, conf.Proxy.NoProxy)
+	})
+
+	t.Run("KO", func(st *testing.T) {
+		provider, err := configuration.NewFile(testdata.Fake.Lorem().Word(), []string{st.TempDir()})
+		require.NoError(st, err)
+
+		_, err = New(provider)
+		require.ErrorContains(st, err, "SENDER.CONFIG.")
+	})
+}
+
 func TestConfig(t *testing.T) {
 	t.Run("OK", func(st *testing.T) {
 		conf := &Config{
@@ -19,6 +63,12 @@ func TestConfig(t *testing.T) {
 		require.NoError(st, conf.Validate())
 	})
 
+	t.Run("OK - body size limit", func(st *testing.T) {
+		require.Equal(st, MaxBodySizeDefault, (&Config{}).BodySizeLimit())
+		require.Equal(st, int64(64), (&Config{MaxBodySize: 64}).BodySizeLimit())
+		require.Equal(st, MaxBodySizeUnlimited, (&Config{MaxBodySize: MaxBodySizeUnlimited}).BodySizeLimit())
+	})
+
 	t.Run("KO ", func(st *testing.T) {
 		conf := &Config{}
 		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.")
@@ -30,4 +80,56 @@ func TestConfig(t *testing.T) {
 		}
 		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.RETRY")
 	})
+
+	t.Run("KO - max body size error", func(st *testing.T) {
+		conf := &Config{
+			Timeout:     testdata.Fake.Int64Between(1000, 10000),
+			Retry:       Retry{Count: 1, WaitTime: 500},
+			MaxBodySize: -2,
+		}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.MAX_BODY_SIZE")
+	})
+
+	t.Run("KO - egress error", func(st *testing.T) {
+		conf := &Config{
+			Timeout: testdata.Fake.Int64Between(1000, 10000),
+			Retry:   Retry{Count: 1, WaitTime: 500},
+			Egress:  &Egress{Ports: []int{0}},
+		}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.EGRESS.PORTS[0]")
+	})
+
+	t.Run("KO - tls error", func(st *testing.T) {
+		conf := &Config{
+			Timeout: testdata.Fake.Int64Between(1000, 10000),
+			Retry:   Retry{Count: 1, WaitTime: 500},
+			Tls:     &Tls{Cert: "client.pem"},
+		}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.TLS.CERT")
+
+		conf.Tls = &Tls{MinVersion: "1.4"}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.TLS.MIN_VERSION")
+	})
+
+	t.Run("KO - proxy error", func(st *testing.T) {
+		conf := &Config{
+			Timeout: testdata.Fake.Int64Between(1000, 10000),
+			Retry:   Retry{Count: 1, WaitTime: 500},
+			Proxy:   &Proxy{Uri: "ftp://proxy.local:21"},
+		}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.PROXY.URI")
+
+		conf.Proxy = &Proxy{Uri: "http://proxy.local:3128", NoProxy: []string{""}}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.PROXY.NO_PROXY[0]")
+	})
+
+	t.Run("KO - proxy with egress", func(st *testing.T) {
+		conf := &Config{
+			Timeout: testdata.Fake.Int64Between(1000, 10000),
+			Retry:   Retry{Count: 1, WaitTime: 500},
+			Proxy:   &Proxy{Uri: "http://proxy.local:3128"},
+			Egress:  &Egress{},
+		}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.PROXY")
+	})
 }
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/kanthorlabs/common/validator"
+)
+
+// Egress restricts where the sender could send requests to.
+// Loopback, link-local, private and cloud metadata ranges are blocked by default
+type Egress struct {
+	// Allow are CIDRs that are allowed even if they are blocked by default
+	Allow []string `json:"allow" yaml:"allow" mapstructure:"allow"`
+	// Deny are CIDRs that are blocked in addition to the default ones, they take precedence over the allowed ones
+	Deny []string `json:"deny" yaml:"deny" mapstructure:"deny"`
+	// Ports are the only ports we could send requests to, any port is allowed when it is empty
+	Ports []int `json:"ports" yaml:"ports" mapstructure:"ports"`
+}
+
+func (conf *Egress) Validate() error {
+	return validator.Validate(
+		validator.Slice(conf.Allow, func(i int, item *string) error {
+			return cidr(fmt.Sprintf("SENDER.CONFIG.EGRESS.ALLOW[%d]", i), *item)
+		}),
+		validator.Slice(conf.Deny, func(i int, item *string) error {
+			return cidr(fmt.Sprintf("SENDER.CONFIG.EGRESS.DENY[%d]", i), *item)
+		}),
+		validator.Slice(conf.Ports, func(i int, item *int) error {
+			return validator.NumberInRange(fmt.Sprintf("SENDER.CONFIG.EGRESS.PORTS[%d]", i), *item, 1, 65535)()
+		}),
+	)
+}
+
+func cidr(prop, value string) error {
+	if _, err := netip.ParsePrefix(value); err != nil {
+		return fmt.Errorf("%s must be a valid CIDR: %w", prop, err)
+	}
+	return nil
+}
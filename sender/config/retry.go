@@ -2,14 +2,46 @@ package config
 
 import "github.com/kanthorlabs/common/validator"
 
+// MaxWaitTimeDefault caps the backoff in milliseconds when MaxWaitTime is not set
+var MaxWaitTimeDefault int64 = 30000
+
 type Retry struct {
-	Count    int   `json:"count" yaml:"count" mapstructure:"count"`
+	// Count is the maximum number of retries, the first attempt is not counted
+	Count int `json:"count" yaml:"count" mapstructure:"count"`
+	// WaitTime is the base of the exponential backoff in milliseconds
 	WaitTime int64 `json:"wait_time" yaml:"wait_time" mapstructure:"wait_time"`
+	// MaxWaitTime caps the backoff and the Retry-After header in milliseconds.
+	// MaxWaitTimeDefault is used when it is not set
+	MaxWaitTime int64 `json:"max_wait_time" yaml:"max_wait_time" mapstructure:"max_wait_time"`
+	// Budget is the total time in milliseconds we could spend on all attempts and waits, zero means no limit
+	Budget int64 `json:"budget" yaml:"budget" mapstructure:"budget"`
+	// NonIdempotent allows to retry POST and PATCH requests that have no Idempotency-Key header
+	NonIdempotent bool `json:"non_idempotent" yaml:"non_idempotent" mapstructure:"non_idempotent"`
 }
 
 func (conf *Retry) Validate() error {
-	return validator.Validate(
+	err := validator.Validate(
 		validator.NumberGreaterThanOrEqual("SENDER.CONFIG.RETRY.COUNT", conf.Count, 0),
 		validator.NumberGreaterThanOrEqual("SENDER.CONFIG.RETRY.WAIT_TIME", conf.WaitTime, 100),
+		validator.NumberGreaterThanOrEqual("SENDER.CONFIG.RETRY.MAX_WAIT_TIME", conf.MaxWaitTime, 0),
+		validator.NumberGreaterThanOrEqual("SENDER.CONFIG.RETRY.BUDGET", conf.Budget, 0),
 	)
+	if err != nil {
+		return err
+	}
+
+	if conf.MaxWaitTime > 0 {
+		if err := validator.NumberGreaterThanOrEqual("SENDER.CONFIG.RETRY.MAX_WAIT_TIME", conf.MaxWaitTime, conf.WaitTime)(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitTimeCap returns the cap of the backoff and the Retry-After header in milliseconds
+func (conf *Retry) WaitTimeCap() int64 {
+	if conf.MaxWaitTime > 0 {
+		return conf.MaxWaitTime
+	}
+	return max(MaxWaitTimeDefault, conf.WaitTime)
 }
@@ -7,6 +7,24 @@ import (
 )
 
 func TestRetry(t *testing.T) {
-	conf := Retry{}
-	require.ErrorContains(t, conf.Validate(), "SENDER.CONFIG.RETRY")
+	t.Run("OK", func(st *testing.T) {
+		conf := Retry{Count: 3, WaitTime: 500, MaxWaitTime: 10000, Budget: 60000}
+		require.NoError(st, conf.Validate())
+	})
+
+	t.Run("OK - wait time cap", func(st *testing.T) {
+		require.Equal(st, int64(10000), (&Retry{WaitTime: 500, MaxWaitTime: 10000}).WaitTimeCap())
+		require.Equal(st, MaxWaitTimeDefault, (&Retry{WaitTime: 500}).WaitTimeCap())
+		require.Equal(st, MaxWaitTimeDefault*2, (&Retry{WaitTime: MaxWaitTimeDefault * 2}).WaitTimeCap())
+	})
+
+	t.Run("KO", func(st *testing.T) {
+		conf := Retry{}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.RETRY")
+	})
+
+	t.Run("KO - max wait time error", func(st *testing.T) {
+		conf := Retry{Count: 3, WaitTime: 500, MaxWaitTime: 100}
+		require.ErrorContains(st, conf.Validate(), "SENDER.CONFIG.RETRY.MAX_WAIT_TIME")
+	})
 }
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+
+	"github.com/kanthorlabs/common/validator"
+)
+
+// TlsVersions are the supported values of Tls.MinVersion
+var TlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type Tls struct {
+	// Ca is the path of a PEM bundle of certificate authorities we trust in addition to the system ones
+	Ca string `json:"ca" yaml:"ca" mapstructure:"ca"`
+	// Cert and Key are paths of the PEM client certificate and its private key we present to servers that require mTLS
+	Cert string `json:"cert" yaml:"cert" mapstructure:"cert"`
+	Key  string `json:"key" yaml:"key" mapstructure:"key"`
+	// MinVersion is one of 1.0, 1.1, 1.2 and 1.3, the default version of Go is used when it is not set
+	MinVersion string `json:"min_version" yaml:"min_version" mapstructure:"min_version"`
+	// ServerName overrides the name we use to verify the certificate of servers
+	ServerName string `json:"server_name" yaml:"server_name" mapstructure:"server_name"`
+	// InsecureSkipVerify disables the verification of certificates of servers, use it for development only
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify"`
+}
+
+func (conf *Tls) Validate() error {
+	return validator.Validate(
+		func() error {
+			if conf.MinVersion == "" {
+				return nil
+			}
+			return validator.StringOneOf("SENDER.CONFIG.TLS.MIN_VERSION", conf.MinVersion, []string{"1.0", "1.1", "1.2", "1.3"})()
+		},
+		func() error {
+			if (conf.Cert == "") != (conf.Key == "") {
+				return errors.New("SENDER.CONFIG.TLS.CERT and SENDER.CONFIG.TLS.KEY must be set together")
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,145 @@
+package sender
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"syscall"
+
+	"github.com/kanthorlabs/common/sender/config"
+)
+
+// EgressBlockedDefault are the ranges we never send requests to unless they are allowed explicitly
+var EgressBlockedDefault = []netip.Prefix{
+	// IPv4
+	netip.MustParsePrefix("0.0.0.0/8"),       // this network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT, Alibaba Cloud metadata 100.100.100.200
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, AWS/GCP/Azure metadata 169.254.169.254
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments, Oracle Cloud metadata 192.0.0.192
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation (TEST-NET-1)
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation (TEST-NET-2)
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation (TEST-NET-3)
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved and broadcast
+	// IPv6
+	netip.MustParsePrefix("::/128"),        // unspecified
+	netip.MustParsePrefix("::1/128"),       // loopback
+	netip.MustParsePrefix("64:ff9b::/96"),  // IPv4/IPv6 translation
+	netip.MustParsePrefix("100::/64"),      // discard-only
+	netip.MustParsePrefix("2001::/32"),     // Teredo, embeds an IPv4 address
+	netip.MustParsePrefix("2001:db8::/32"), // documentation
+	netip.MustParsePrefix("2002::/16"),     // 6to4, embeds an IPv4 address
+	netip.MustParsePrefix("fc00::/7"),      // unique local, AWS metadata fd00:ec2::254
+	netip.MustParsePrefix("fe80::/10"),     // link-local
+	netip.MustParsePrefix("ff00::/8"),      // multicast
+}
+
+// EgressError is the error of requests that are denied by the egress policy, it matches ErrEgressDenied
+type EgressError struct {
+	Address string
+	Reason  string
+}
+
+func (err *EgressError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", ErrEgressDenied.Error(), err.Address, err.Reason)
+}
+
+func (err *EgressError) Is(target error) bool {
+	return target == ErrEgressDenied
+}
+
+// NewEgressPolicy creates the policy that checks every address we dial,
+// so the resolved IP is the one that is checked and DNS rebinding could not bypass it
+func NewEgressPolicy(conf *config.Egress) (*EgressPolicy, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	policy := &EgressPolicy{ports: conf.Ports}
+	for _, cidr := range conf.Allow {
+		policy.allow = append(policy.allow, netip.MustParsePrefix(cidr))
+	}
+	for _, cidr := range conf.Deny {
+		policy.deny = append(policy.deny, netip.MustParsePrefix(cidr))
+	}
+	return policy, nil
+}
+
+type EgressPolicy struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+	ports []int
+}
+
+// Check decides whether we could connect to the address
+func (policy *EgressPolicy) Check(addr netip.AddrPort) error {
+	ip := addr.Addr().Unmap()
+
+	if len(policy.ports) > 0 && !slices.Contains(policy.ports, int(addr.Port())) {
+		return &EgressError{Address: addr.String(), Reason: "port is not allowed"}
+	}
+	if contains(policy.deny, ip) {
+		return &EgressError{Address: addr.String(), Reason: "address is denied"}
+	}
+	if contains(policy.allow, ip) {
+		return nil
+	}
+	if contains(EgressBlockedDefault, ip) {
+		return &EgressError{Address: addr.String(), Reason: "address is blocked by default"}
+	}
+	return nil
+}
+
+// Control is the net.Dialer Control function that checks the address right before we connect to it
+func (policy *EgressPolicy) Control(network, address string, c syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return &EgressError{Address: address, Reason: err.Error()}
+	}
+	return policy.Check(addr)
+}
+
+// Redirect checks every redirect hop before we follow it.
+// The address of the hop is checked again when we dial it
+func (policy *EgressPolicy) Redirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return &EgressError{Address: req.URL.String(), Reason: "scheme is not allowed"}
+	}
+
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	if len(policy.ports) > 0 {
+		p, err := strconv.Atoi(port)
+		if err != nil || !slices.Contains(policy.ports, p) {
+			return &EgressError{Address: req.URL.Host, Reason: "port is not allowed"}
+		}
+	}
+
+	// literal IPs could be checked right away
+	if ip, err := netip.ParseAddr(req.URL.Hostname()); err == nil {
+		p, _ := strconv.Atoi(port)
+		return policy.Check(netip.AddrPortFrom(ip, uint16(p)))
+	}
+	return nil
+}
+
+func contains(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
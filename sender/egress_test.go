@@ -0,0 +1,154 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressPolicy_Check(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		policy, err := NewEgressPolicy(&config.Egress{})
+		require.NoError(st, err)
+
+		for _, addr := range []string{"93.184.216.34:443", "[2606:2800:220:1:248:1893:25c8:1946]:443", "8.8.8.8:53"} {
+			require.NoError(st, policy.Check(netip.MustParseAddrPort(addr)), addr)
+		}
+	})
+
+	t.Run("OK - allow", func(st *testing.T) {
+		policy, err := NewEgressPolicy(&config.Egress{Allow: []string{"10.1.0.0/16"}})
+		require.NoError(st, err)
+
+		require.NoError(st, policy.Check(netip.MustParseAddrPort("10.1.2.3:80")))
+		require.ErrorIs(st, policy.Check(netip.MustParseAddrPort("10.2.2.3:80")), ErrEgressDenied)
+	})
+
+	t.Run("KO - blocked by default", func(st *testing.T) {
+		policy, err := NewEgressPolicy(&config.Egress{})
+		require.NoError(st, err)
+
+		addrs := []string{
+			"127.0.0.1:80",
+			"10.0.0.1:80",
+			"172.16.0.1:80",
+			"192.168.1.1:80",
+			"169.254.169.254:80",
+			"100.100.100.200:80",
+			"0.0.0.0:80",
+			"[::1]:80",
+			"[fe80::1]:80",
+			"[fd00:ec2::254]:80",
+			"192.0.2.1:80",
+			"198.51.100.1:80",
+			"203.0.113.1:80",
+			"[2001:db8::1]:80",
+			"[100::1]:80",
+			// Teredo and 6to4 addresses embed IPv4 addresses, 127.0.0.1 and 10.0.0.1 here
+			"[2001:0:4136:e378:8000:63bf:80ff:fffe]:80",
+			"[2002:7f00:1::1]:80",
+			"[2002:a00:1::1]:80",
+			// IPv4-mapped IPv6 addresses must not bypass the policy
+			"[::ffff:127.0.0.1]:80",
+		}
+		for _, addr := range addrs {
+			require.ErrorIs(st, policy.Check(netip.MustParseAddrPort(addr)), ErrEgressDenied, addr)
+		}
+	})
+
+	t.Run("KO - deny takes precedence", func(st *testing.T) {
+		policy, err := NewEgressPolicy(&config.Egress{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.0/24", "8.8.8.0/24"}})
+		require.NoError(st, err)
+
+		require.ErrorIs(st, policy.Check(netip.MustParseAddrPort("10.0.0.1:80")), ErrEgressDenied)
+		require.ErrorIs(st, policy.Check(netip.MustParseAddrPort("8.8.8.8:53")), ErrEgressDenied)
+	})
+
+	t.Run("KO - port", func(st *testing.T) {
+		policy, err := NewEgressPolicy(&config.Egress{Ports: []int{443}})
+		require.NoError(st, err)
+
+		require.NoError(st, policy.Check(netip.MustParseAddrPort("8.8.8.8:443")))
+		require.ErrorContains(st, policy.Check(netip.MustParseAddrPort("8.8.8.8:80")), "port is not allowed")
+	})
+
+	t.Run("KO - configuration error", func(st *testing.T) {
+		_, err := NewEgressPolicy(&config.Egress{Allow: []string{"10.0.0.0"}})
+		require.ErrorContains(st, err, "SENDER.CONFIG.EGRESS.ALLOW[0]")
+	})
+}
+
+func TestEgressPolicy_Redirect(t *testing.T) {
+	policy, err := NewEgressPolicy(&config.Egress{Ports: []int{443}})
+	require.NoError(t, err)
+
+	redirect := func(uri string) error {
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		require.NoError(t, err)
+		return policy.Redirect(req, nil)
+	}
+
+	require.NoError(t, redirect("https://example.com/path"))
+	require.ErrorIs(t, redirect("http://example.com/path"), ErrEgressDenied)
+	require.ErrorIs(t, redirect("https://169.254.169.254/latest/meta-data"), ErrEgressDenied)
+	require.ErrorIs(t, redirect("ftp://example.com:443/file"), ErrEgressDenied)
+}
+
+func TestEgress(t *testing.T) {
+	server := httpserver()
+	defer server.Close()
+
+	t.Run("OK - allow loopback", func(st *testing.T) {
+		conf := *testconf
+		conf.Egress = &config.Egress{Allow: []string{"127.0.0.0/8"}}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+	})
+
+	t.Run("KO - loopback", func(st *testing.T) {
+		conf := *testconf
+		conf.Egress = &config.Egress{}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.ErrorIs(st, err, ErrEgressDenied)
+
+		// the name is resolved before we check it
+		uri := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: uri + "/200"})
+		require.ErrorIs(st, err, ErrEgressDenied)
+	})
+
+	t.Run("KO - redirect", func(st *testing.T) {
+		another := httpserver()
+		defer another.Close()
+
+		u, err := url.Parse(server.URL)
+		require.NoError(st, err)
+		port, err := strconv.Atoi(u.Port())
+		require.NoError(st, err)
+
+		conf := *testconf
+		conf.Egress = &config.Egress{Allow: []string{"127.0.0.0/8"}, Ports: []int{port}}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		to := url.QueryEscape(another.URL + "/200")
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/redirect?to=" + to})
+		require.ErrorIs(st, err, ErrEgressDenied)
+	})
+}
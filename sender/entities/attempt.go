@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// Attempt is a single request that is sent for a Request, Status is -1 when the request could not reach the server
+type Attempt struct {
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// TransportError is the category of the Error, see Response.TransportError
+	TransportError string        `json:"transport_error,omitempty"`
+	Duration       time.Duration `json:"duration"`
+	Timing         *Timing       `json:"timing,omitempty"`
+	// Wait is how long we waited after this attempt before the next one, it is zero for the last attempt
+	Wait time.Duration `json:"wait"`
+}
@@ -1,21 +1,46 @@
 package entities
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/kanthorlabs/common/validator"
 )
 
+// Methods are the methods we could send, CONNECT is excluded because it opens a tunnel instead of exchanging a request and a response
+var Methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
 type Request struct {
 	Method  string      `json:"method"`
 	Headers http.Header `json:"headers"`
 	Uri     string      `json:"uri"`
 	Body    []byte      `json:"body"`
+	// Reader is the body that is streamed instead of being copied into memory, Body must be empty when it is set.
+	// The request is retried only when the reader implements io.Seeker because it is rewound to the start before every retry
+	Reader io.Reader `json:"-"`
+	// Stream asks for the body of the response as Response.Stream instead of Response.Body
+	Stream bool `json:"-"`
 }
 
 func (req *Request) Validate() error {
 	return validator.Validate(
-		validator.StringOneOf("SENDER.REQUEST.METHOD", req.Method, []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch}),
+		validator.StringOneOf("SENDER.REQUEST.METHOD", req.Method, Methods),
 		validator.StringUri("SENDER.REQUEST.URI", req.Uri),
+		func() error {
+			if req.Reader != nil && len(req.Body) > 0 {
+				return errors.New("SENDER.REQUEST.BODY must be empty when SENDER.REQUEST.READER is set")
+			}
+			return nil
+		},
 	)
 }
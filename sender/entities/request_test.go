@@ -1,12 +1,33 @@
 package entities
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
 func TestRequest(t *testing.T) {
-	req := &Request{}
-	require.ErrorContains(t, req.Validate(), "SENDER.REQUEST")
+	t.Run("OK", func(st *testing.T) {
+		for _, method := range Methods {
+			req := &Request{Method: method, Uri: "https://example.com"}
+			require.NoError(st, req.Validate())
+		}
+	})
+
+	t.Run("KO", func(st *testing.T) {
+		req := &Request{}
+		require.ErrorContains(st, req.Validate(), "SENDER.REQUEST")
+	})
+
+	t.Run("KO - connect error", func(st *testing.T) {
+		req := &Request{Method: http.MethodConnect, Uri: "https://example.com"}
+		require.ErrorContains(st, req.Validate(), "SENDER.REQUEST.METHOD")
+	})
+
+	t.Run("KO - body and reader", func(st *testing.T) {
+		req := &Request{Method: http.MethodPost, Uri: "https://example.com", Body: []byte("{}"), Reader: strings.NewReader("{}")}
+		require.ErrorContains(st, req.Validate(), "SENDER.REQUEST.BODY")
+	})
 }
@@ -1,12 +1,46 @@
 package entities
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
+
+var (
+	// StatusTransportError is the status of responses that could not reach the server, the error is in the body
+	StatusTransportError = -1
+	// StatusCircuitOpen is the status of responses that are not sent because the circuit of the host is open
+	StatusCircuitOpen = -2
+)
+
+// Categories of transport errors, they tell why the request could not reach the server
+var (
+	TransportErrorDns              = "dns"
+	TransportErrorConnectRefused   = "connect_refused"
+	TransportErrorTimeout          = "timeout"
+	TransportErrorTls              = "tls"
+	TransportErrorTooManyRedirects = "too_many_redirects"
+	TransportErrorBodyTooLarge     = "body_too_large"
+	TransportErrorCanceled         = "canceled"
+	TransportErrorUnknown          = "unknown"
+)
 
 type Response struct {
 	Status  int         `json:"status"`
 	Headers http.Header `json:"headers"`
 	Uri     string      `json:"uri"`
 	Body    []byte      `json:"body"`
+	// Truncated tells the body is cut at the MaxBodySize of the configuration
+	Truncated bool `json:"truncated,omitempty"`
+	// Stream is the body of the response when the request asks for it with Request.Stream, the caller must close it.
+	// The body is not limited by MaxBodySize, but the timeout of the configuration still applies to reading it
+	Stream io.ReadCloser `json:"-"`
+	// Attempts are the requests we sent to get the response, the last one is the one that is returned
+	Attempts []Attempt `json:"attempts,omitempty"`
+	// TransportError is the category of the error when the request could not reach the server.
+	// Status and Body are still set to -1 and the error message for compatibility
+	TransportError string `json:"transport_error,omitempty"`
+	// Timing is the timing of the last attempt
+	Timing *Timing `json:"timing,omitempty"`
 }
 
 func (entity *Response) Ok() bool {
@@ -21,9 +55,41 @@ func (entity *Response) Ok() bool {
 }
 
 func (entity *Response) StatusText() string {
-	if entity.Status == -1 {
+	if entity.Status < 0 {
 		return string(entity.Body)
 	}
 
 	return http.StatusText(entity.Status)
 }
+
+func (entity *Response) IsTransportError() bool {
+	return entity.TransportError != ""
+}
+
+func (entity *Response) IsDnsError() bool {
+	return entity.TransportError == TransportErrorDns
+}
+
+func (entity *Response) IsConnectRefused() bool {
+	return entity.TransportError == TransportErrorConnectRefused
+}
+
+func (entity *Response) IsTimeout() bool {
+	return entity.TransportError == TransportErrorTimeout
+}
+
+func (entity *Response) IsTlsError() bool {
+	return entity.TransportError == TransportErrorTls
+}
+
+func (entity *Response) IsTooManyRedirects() bool {
+	return entity.TransportError == TransportErrorTooManyRedirects
+}
+
+func (entity *Response) IsBodyTooLarge() bool {
+	return entity.Truncated || entity.TransportError == TransportErrorBodyTooLarge
+}
+
+func (entity *Response) IsCanceled() bool {
+	return entity.TransportError == TransportErrorCanceled
+}
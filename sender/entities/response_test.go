@@ -25,4 +25,30 @@ func TestResponse(t *testing.T) {
 	exception := &Response{Status: -1, Body: testdata.Fake.Lorem().Bytes(100)}
 	require.Equal(t, exception.StatusText(), string(exception.Body))
 
+	truncated := &Response{Status: http.StatusOK, Truncated: true}
+	require.True(t, truncated.IsBodyTooLarge())
+	require.False(t, truncated.IsTransportError())
+}
+
+func TestResponse_TransportError(t *testing.T) {
+	predicates := map[string]func(res *Response) bool{
+		TransportErrorDns:              (*Response).IsDnsError,
+		TransportErrorConnectRefused:   (*Response).IsConnectRefused,
+		TransportErrorTimeout:          (*Response).IsTimeout,
+		TransportErrorTls:              (*Response).IsTlsError,
+		TransportErrorTooManyRedirects: (*Response).IsTooManyRedirects,
+		TransportErrorBodyTooLarge:     (*Response).IsBodyTooLarge,
+		TransportErrorCanceled:         (*Response).IsCanceled,
+	}
+
+	for category := range predicates {
+		res := &Response{Status: StatusTransportError, TransportError: category}
+		require.True(t, res.IsTransportError())
+
+		for other, predicate := range predicates {
+			require.Equal(t, category == other, predicate(res), category)
+		}
+	}
+
+	require.False(t, (&Response{Status: http.StatusOK}).IsTransportError())
 }
@@ -0,0 +1,18 @@
+package entities
+
+import "time"
+
+// Timing is where the time of an attempt went.
+// Dns, Connect and Tls are zero when the connection is reused, and they are summed up when we follow redirects to other hosts
+type Timing struct {
+	Dns       time.Duration `json:"dns"`
+	Connect   time.Duration `json:"connect"`
+	Tls       time.Duration `json:"tls"`
+	FirstByte time.Duration `json:"first_byte"`
+	Total     time.Duration `json:"total"`
+	// RemoteIp is the IP of the server we received the response from
+	RemoteIp string `json:"remote_ip,omitempty"`
+	// Protocol is the protocol of the response, for example HTTP/1.1 or HTTP/2.0
+	Protocol string `json:"protocol,omitempty"`
+	Reused   bool   `json:"reused"`
+}
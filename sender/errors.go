@@ -0,0 +1,20 @@
+package sender
+
+import "errors"
+
+var (
+	// ErrCircuitOpen is returned along with a response that has the StatusCircuitOpen status
+	ErrCircuitOpen = errors.New("SENDER.CIRCUIT_BREAKER.OPEN.ERROR")
+	// ErrEgressDenied is matched by errors of requests that are denied by the egress policy
+	ErrEgressDenied = errors.New("SENDER.EGRESS.DENIED.ERROR")
+	// ErrTooManyRedirects is returned when the server redirects us more than RedirectMax times
+	ErrTooManyRedirects = errors.New("SENDER.TOO_MANY_REDIRECTS.ERROR")
+	// ErrBodyTooLarge is recorded on attempts whose response body is truncated at the configured limit
+	ErrBodyTooLarge = errors.New("SENDER.BODY_TOO_LARGE.ERROR")
+	// ErrUnixTargetDenied is returned when unix URIs are not allowed or a request or a redirect targets a unix domain socket without a unix URI
+	ErrUnixTargetDenied = errors.New("SENDER.UNIX.TARGET_DENIED.ERROR")
+	// ErrTlsMalformed is returned when the certificate authorities or the client certificate could not be loaded
+	ErrTlsMalformed = errors.New("SENDER.TLS.MALFORMED.ERROR")
+
+	errUnhealthy = errors.New("SENDER.CIRCUIT_BREAKER.UNHEALTHY.ERROR")
+)
@@ -2,81 +2,189 @@ package sender
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/kanthorlabs/common/circuitbreaker"
 	"github.com/kanthorlabs/common/logging"
 	"github.com/kanthorlabs/common/sender/config"
 	"github.com/kanthorlabs/common/sender/entities"
 )
 
-func NewHttp(conf *config.Config, logger logging.Logger) (Send, error) {
+func NewHttp(conf *config.Config, logger logging.Logger, withOptions ...Option) (Send, error) {
 	if err := conf.Validate(); err != nil {
 		return nil, err
 	}
 
+	options := &Options{}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+	if options.CircuitBreaker == nil && conf.CircuitBreaker != nil {
+		cb, err := circuitbreaker.New(conf.CircuitBreaker, logger)
+		if err != nil {
+			return nil, err
+		}
+		options.CircuitBreaker = cb
+	}
+
+	t, redirects, err := transport(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	// we retry by ourselves to follow the retry policy and record every attempt
 	client := resty.New().
 		SetLogger(logger.With("sender", "http")).
 		SetTimeout(time.Millisecond * time.Duration(conf.Timeout)).
-		SetRetryCount(conf.Retry.Count).
-		SetRetryWaitTime(time.Millisecond * time.Duration(conf.Retry.WaitTime)).
-		AddRetryCondition(func(r *resty.Response, err error) bool {
-			status := r.StatusCode()
-			url := r.Request.URL
-			if status >= http.StatusInternalServerError {
-				logger.Warnw("SENDER.RETRYING", "status", status, "url", url)
-				return true
-			}
-			return false
-		}).
+		SetTransport(t).
+		SetRedirectPolicy(redirects...).
 		SetHeaders(conf.Headers)
 
-	return func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
-		req := client.R().
-			SetContext(ctx).
-			SetHeaderMultiValues(r.Headers)
-
-		var err = r.Validate()
-		if err != nil {
+	var send Send = func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+		if err := r.Validate(); err != nil {
 			return nil, err
 		}
+		// the internal host of unix domain sockets is only produced by unix URIs which must be allowed explicitly
+		if unixforged(r.Uri) || (!options.UnixSockets && unixuri(r.Uri)) {
+			return nil, fmt.Errorf("%w: %s", ErrUnixTargetDenied, RedactUri(r.Uri))
+		}
 
 		if r.Body == nil {
 			r.Body = make([]byte, 0)
 		}
 
-		var res *resty.Response
-		if r.Method == http.MethodGet {
-			res, err = req.Get(r.Uri)
-		}
-		if r.Method == http.MethodPost {
-			res, err = req.SetBody(r.Body).Post(r.Uri)
-		}
-		if r.Method == http.MethodPut {
-			res, err = req.SetBody(r.Body).Put(r.Uri)
-		}
-		if r.Method == http.MethodPatch {
-			res, err = req.SetBody(r.Body).Patch(r.Uri)
+		started := time.Now()
+		var attempts []entities.Attempt
+		for {
+			traced, trace := newtracer(ctx)
+			res, err := execute(traced, client, r)
+			// requests that are denied by the egress policy or target sockets without unix URIs will never be allowed, there is no point to return a response
+			if errors.Is(err, ErrEgressDenied) || errors.Is(err, ErrUnixTargetDenied) {
+				return nil, err
+			}
+
+			var payload []byte
+			var truncated bool
+			var stream io.ReadCloser
+			if err == nil {
+				if r.Stream {
+					stream = res.RawBody()
+				} else {
+					payload, truncated, err = readbody(res.RawBody(), conf.BodySizeLimit())
+				}
+			}
+
+			var protocol string
+			if res != nil && res.RawResponse != nil {
+				protocol = res.RawResponse.Proto
+			}
+			attempt := entities.Attempt{Timing: trace.done(protocol)}
+			attempt.Duration = attempt.Timing.Total
+
+			var raw *http.Response
+			response := &entities.Response{Timing: attempt.Timing}
+			if err != nil {
+				// catch the error and return the response
+				response.Status = entities.StatusTransportError
+				response.Headers = make(http.Header)
+				response.Uri = r.Uri
+				response.Body = []byte(err.Error())
+				response.TransportError = Classify(err)
+				attempt.Error = err.Error()
+				attempt.TransportError = response.TransportError
+			} else {
+				raw = res.RawResponse
+				response.Status = res.StatusCode()
+				response.Headers = res.Header()
+				// follow redirect url and got final url
+				// most time the response url is same as request url
+				response.Uri = unixorigin(res.RawResponse.Request.URL)
+				response.Body = payload
+				response.Truncated = truncated
+				if truncated {
+					attempt.Error = ErrBodyTooLarge.Error()
+				}
+				response.Stream = stream
+				// HEAD responses never have a body, make sure we return an empty one instead of nil
+				if response.Body == nil || r.Method == http.MethodHead {
+					response.Body = []byte{}
+				}
+			}
+			attempt.Status = response.Status
+			attempts = append(attempts, attempt)
+			response.Attempts = attempts
+
+			if ctx.Err() != nil {
+				return response, nil
+			}
+			duration, retry := Retryable(&conf.Retry, r, raw, err, len(attempts), time.Since(started))
+			if !retry {
+				return response, nil
+			}
+			if response.Stream != nil {
+				response.Stream.Close()
+			}
+			if seeker, ok := r.Reader.(io.Seeker); ok {
+				if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+					return response, nil
+				}
+			}
+
+			logger.Warnw("SENDER.RETRYING", "status", response.Status, "url", r.Uri, "attempt", len(attempts), "wait", duration.String())
+			attempts[len(attempts)-1].Wait = duration
+			if wait(ctx, duration) != nil {
+				return response, nil
+			}
 		}
+	}
 
-		// catch the error and return the response
-		if err != nil {
-			return &entities.Response{
-				Status:  -1,
-				Headers: make(http.Header),
-				Uri:     r.Uri,
-				Body:    []byte(err.Error()),
-			}, nil
+	if options.CircuitBreaker != nil {
+		send = breaker(send, options.CircuitBreaker, options.CircuitBreakerKey)
+	}
+	return Chain(send, options.Middlewares...), nil
+}
+
+func execute(ctx context.Context, client *resty.Client, r *entities.Request) (*resty.Response, error) {
+	ctx, uri, unix := unixtarget(ctx, r.Uri)
+
+	// we read the body by ourselves to limit its size or to hand it over as a stream
+	req := client.R().
+		SetContext(ctx).
+		SetHeaderMultiValues(r.Headers).
+		SetDoNotParseResponse(true)
+
+	if unix && r.Headers.Get("Host") == "" {
+		req.SetHeader("Host", UnixHost)
+	}
+
+	// resty drops the body of GET, HEAD and OPTIONS requests by itself, TRACE must not have one either
+	if r.Method != http.MethodTrace {
+		if r.Reader != nil {
+			req.SetBody(r.Reader)
+		} else {
+			req.SetBody(r.Body)
 		}
+	}
+	return req.Execute(r.Method, uri)
+}
+
+// readbody reads the body up to the limit and tells whether the rest is dropped, config.MaxBodySizeUnlimited means no limit
+func readbody(body io.ReadCloser, limit int64) ([]byte, bool, error) {
+	defer body.Close()
 
-		return &entities.Response{
-			Status:  res.StatusCode(),
-			Headers: res.Header(),
-			// follow redirect url and got final url
-			// most time the response url is same as request url
-			Uri:  res.RawResponse.Request.URL.String(),
-			Body: res.Body(),
-		}, nil
-	}, nil
+	if limit == config.MaxBodySizeUnlimited {
+		data, err := io.ReadAll(body)
+		return data, false, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if int64(len(data)) > limit {
+		return data[:limit], true, err
+	}
+	return data, false, err
 }
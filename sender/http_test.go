@@ -2,12 +2,18 @@ package sender
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/kanthorlabs/common/sender/config"
 	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
 	"github.com/kanthorlabs/common/testify"
 	"github.com/stretchr/testify/require"
 )
@@ -21,10 +27,13 @@ func TestNewHttp(t *testing.T) {
 		defer server.Close()
 
 		methods := map[string]bool{
-			http.MethodGet:   false,
-			http.MethodPost:  true,
-			http.MethodPut:   true,
-			http.MethodPatch: true,
+			http.MethodGet:     false,
+			http.MethodPost:    true,
+			http.MethodPut:     true,
+			http.MethodPatch:   true,
+			http.MethodDelete:  true,
+			http.MethodOptions: false,
+			http.MethodTrace:   false,
 		}
 
 		for method, hasBody := range methods {
@@ -47,10 +56,45 @@ func TestNewHttp(t *testing.T) {
 		}
 	})
 
+	t.Run("OK - head", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		req := &entities.Request{
+			Method: http.MethodHead,
+			Uri:    server.URL + "/200",
+		}
+		res, err := send(context.Background(), req)
+		require.NoError(st, err)
+
+		require.Equal(st, http.StatusOK, res.Status)
+		require.Equal(st, "application/json", res.Headers.Get("Content-Type"))
+		require.NotNil(st, res.Body)
+		require.Empty(st, res.Body)
+	})
+
 	t.Run("OK - retry", func(st *testing.T) {
 		server := httpserver()
 		defer server.Close()
 
+		req := &entities.Request{
+			Method: http.MethodPut,
+			Uri:    server.URL + "/500",
+			Body:   []byte(fmt.Sprintf(`{"id":"%s"}`, uuid.NewString())),
+		}
+		res, err := send(context.Background(), req)
+		require.NoError(st, err)
+
+		require.Equal(st, http.StatusInternalServerError, res.Status)
+		require.Len(st, res.Attempts, testconf.Retry.Count+1)
+		require.Equal(st, http.StatusInternalServerError, res.Attempts[0].Status)
+		require.Zero(st, res.Attempts[len(res.Attempts)-1].Wait)
+	})
+
+	t.Run("OK - no retry for non-idempotent methods", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
 		id := uuid.NewString()
 		req := &entities.Request{
 			Method: http.MethodPost,
@@ -58,9 +102,40 @@ func TestNewHttp(t *testing.T) {
 			Body:   []byte(fmt.Sprintf(`{"id":"%s"}`, id)),
 		}
 		res, err := send(context.Background(), req)
-		require.NoError(t, err)
+		require.NoError(st, err)
 
 		require.Equal(st, http.StatusInternalServerError, res.Status)
+		require.Len(st, res.Attempts, 1)
+	})
+
+	t.Run("OK - retry non-idempotent methods with idempotency key", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		req := &entities.Request{
+			Method:  http.MethodPost,
+			Uri:     server.URL + "/500",
+			Headers: http.Header{HeaderIdempotencyKey: []string{uuid.NewString()}},
+		}
+		res, err := send(context.Background(), req)
+		require.NoError(st, err)
+		require.Len(st, res.Attempts, testconf.Retry.Count+1)
+	})
+
+	t.Run("OK - retry after", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		req := &entities.Request{
+			Method: http.MethodPut,
+			Uri:    server.URL + "/429?after=1",
+		}
+		res, err := send(context.Background(), req)
+		require.NoError(st, err)
+
+		require.Equal(st, http.StatusTooManyRequests, res.Status)
+		require.Len(st, res.Attempts, testconf.Retry.Count+1)
+		require.Equal(st, time.Second, res.Attempts[0].Wait)
 	})
 
 	t.Run("KO - request validation error", func(st *testing.T) {
@@ -89,5 +164,95 @@ func TestNewHttp(t *testing.T) {
 		require.Equal(st, -1, res.Status)
 		require.Empty(st, res.Headers)
 		require.NotEmpty(st, res.Body)
+		require.True(st, res.IsTimeout())
+	})
+}
+
+func TestNewHttp_Body(t *testing.T) {
+	server := httpserver()
+	defer server.Close()
+
+	payload := testdata.Fake.Lorem().Sentence(100)
+
+	t.Run("OK - max body size", func(st *testing.T) {
+		conf := *testconf
+		conf.MaxBodySize = 64
+		send, err := NewHttp(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: server.URL + "/200", Body: []byte(payload)})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+		require.Len(st, res.Body, int(conf.MaxBodySize))
+		require.True(st, res.Truncated)
+		require.True(st, res.IsBodyTooLarge())
+		require.False(st, res.IsTransportError())
+		require.Equal(st, ErrBodyTooLarge.Error(), res.Attempts[0].Error)
+	})
+
+	t.Run("OK - unlimited", func(st *testing.T) {
+		conf := *testconf
+		conf.MaxBodySize = config.MaxBodySizeUnlimited
+		send, err := NewHttp(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: server.URL + "/200", Body: []byte(payload)})
+		require.NoError(st, err)
+		require.Greater(st, len(res.Body), len(payload))
+		require.False(st, res.Truncated)
+	})
+
+	t.Run("OK - stream", func(st *testing.T) {
+		conf := *testconf
+		conf.MaxBodySize = 64
+		send, err := NewHttp(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: server.URL + "/200", Body: []byte(payload), Stream: true})
+		require.NoError(st, err)
+		require.Empty(st, res.Body)
+		require.NotNil(st, res.Stream)
+		defer res.Stream.Close()
+
+		var data map[string]any
+		require.NoError(st, json.NewDecoder(res.Stream).Decode(&data))
+		require.Equal(st, payload, data["req_body"])
+	})
+
+	t.Run("OK - reader", func(st *testing.T) {
+		send, err := NewHttp(testconf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: server.URL + "/200", Reader: strings.NewReader(payload)})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+
+		var data map[string]any
+		require.NoError(st, json.Unmarshal(res.Body, &data))
+		require.Equal(st, payload, data["req_body"])
+	})
+
+	t.Run("OK - retry reader that could be rewound", func(st *testing.T) {
+		send, err := NewHttp(testconf, testify.Logger())
+		require.NoError(st, err)
+
+		req := &entities.Request{Method: http.MethodPut, Uri: server.URL + "/500", Reader: strings.NewReader(payload)}
+		res, err := send(context.Background(), req)
+		require.NoError(st, err)
+		require.Len(st, res.Attempts, testconf.Retry.Count+1)
+
+		var data map[string]any
+		require.NoError(st, json.Unmarshal(res.Body, &data))
+		require.Equal(st, payload, data["req_body"])
+	})
+
+	t.Run("OK - no retry for reader that could not be rewound", func(st *testing.T) {
+		send, err := NewHttp(testconf, testify.Logger())
+		require.NoError(st, err)
+
+		req := &entities.Request{Method: http.MethodPut, Uri: server.URL + "/500", Reader: io.MultiReader(strings.NewReader(payload))}
+		res, err := send(context.Background(), req)
+		require.NoError(st, err)
+		require.Len(st, res.Attempts, 1)
 	})
 }
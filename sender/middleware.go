@@ -0,0 +1,207 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanthorlabs/common/logging"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+var (
+	HeaderRequestId = "X-Request-Id"
+	Redacted        = "[REDACTED]"
+	// RedactedHeaders are never logged as they are
+	RedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "Webhook-Signature"}
+	// RedactedQueries are the query parameters of uris that are never logged as they are
+	RedactedQueries = []string{"token", "access_token", "api_key", "apikey", "key", "secret", "password", "signature", "sig", "code"}
+)
+
+// Middleware wraps a Send to do something before or after the request is sent
+type Middleware func(next Send) Send
+
+// Chain wraps the send with the middlewares, the first middleware is the outermost one
+func Chain(send Send, middlewares ...Middleware) Send {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		send = middlewares[i](send)
+	}
+	return send
+}
+
+// RequestId sets the X-Request-Id header of requests that do not have one
+func RequestId() Middleware {
+	return func(next Send) Send {
+		return func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			if r.Headers.Get(HeaderRequestId) != "" {
+				return next(ctx, r)
+			}
+
+			req := clone(r)
+			req.Headers.Set(HeaderRequestId, uuid.NewString())
+			return next(ctx, req)
+		}
+	}
+}
+
+// Headers sets the default headers of requests, headers of the request take precedence over the default ones
+func Headers(defaults http.Header) Middleware {
+	return func(next Send) Send {
+		return func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			req := clone(r)
+			for name, values := range defaults {
+				if req.Headers.Get(name) == "" {
+					req.Headers[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+				}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// Redactor hides sensitive data of a body before it is logged
+type Redactor func(body []byte) []byte
+
+// RedactJson replaces the values of the given fields of JSON bodies at any depth, other bodies are replaced entirely
+func RedactJson(fields ...string) Redactor {
+	return func(body []byte) []byte {
+		if len(body) == 0 {
+			return body
+		}
+
+		var data any
+		if err := json.Unmarshal(body, &data); err != nil {
+			return []byte(Redacted)
+		}
+
+		redacted, _ := json.Marshal(redact(data, fields))
+		return redacted
+	}
+}
+
+func redact(data any, fields []string) any {
+	switch value := data.(type) {
+	case map[string]any:
+		for k := range value {
+			if slices.ContainsFunc(fields, func(field string) bool { return strings.EqualFold(field, k) }) {
+				value[k] = Redacted
+				continue
+			}
+			value[k] = redact(value[k], fields)
+		}
+	case []any:
+		for i := range value {
+			value[i] = redact(value[i], fields)
+		}
+	}
+	return data
+}
+
+// RedactUri hides the password of the user info and the values of RedactedQueries of the uri.
+// Uris that could not be parsed are replaced entirely
+func RedactUri(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return Redacted
+	}
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+
+	params := strings.Split(u.RawQuery, "&")
+	for i := range params {
+		name, _, _ := strings.Cut(params[i], "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if slices.ContainsFunc(RedactedQueries, func(redacted string) bool { return strings.EqualFold(redacted, name) }) {
+			params[i] = url.QueryEscape(name) + "=" + Redacted
+		}
+	}
+	u.RawQuery = strings.Join(params, "&")
+	return u.Redacted()
+}
+
+// Logging logs every request with its response. Bodies are logged only when the redactor is provided
+func Logging(logger logging.Logger, redactor Redactor) Middleware {
+	logger = logger.With("sender", "logging")
+
+	return func(next Send) Send {
+		return func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			started := time.Now()
+			res, err := next(ctx, r)
+
+			args := []any{
+				"method", r.Method,
+				"uri", RedactUri(r.Uri),
+				"request_headers", headers(r.Headers),
+				"duration", time.Since(started).String(),
+			}
+			if redactor != nil {
+				args = append(args, "request_body", string(redactor(r.Body)))
+			}
+
+			if err != nil {
+				logger.Errorw("SENDER.REQUEST.ERROR", append(args, "error", err.Error())...)
+				return res, err
+			}
+
+			args = append(args, "status", res.Status, "attempts", len(res.Attempts), "response_headers", headers(res.Headers))
+			if res.TransportError != "" {
+				args = append(args, "transport_error", res.TransportError)
+			}
+			if redactor != nil {
+				args = append(args, "response_body", string(redactor(res.Body)))
+			}
+
+			if res.Ok() {
+				logger.Infow("SENDER.REQUEST", args...)
+			} else {
+				logger.Warnw("SENDER.REQUEST.FAILED", args...)
+			}
+			return res, nil
+		}
+	}
+}
+
+// MetricsHook receives the result of every request, the response is nil when the error is not nil
+type MetricsHook func(r *entities.Request, res *entities.Response, err error, duration time.Duration)
+
+// Metrics calls the hook after every request so it could be recorded by any metrics system
+func Metrics(hook MetricsHook) Middleware {
+	return func(next Send) Send {
+		return func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			started := time.Now()
+			res, err := next(ctx, r)
+			hook(r, res, err, time.Since(started))
+			return res, err
+		}
+	}
+}
+
+// clone copies the request and its headers so middlewares never modify the request of the caller
+func clone(r *entities.Request) *entities.Request {
+	req := *r
+	req.Headers = r.Headers.Clone()
+	if req.Headers == nil {
+		req.Headers = make(http.Header)
+	}
+	return &req
+}
+
+func headers(h http.Header) map[string]string {
+	flatten := make(map[string]string, len(h))
+	for name, values := range h {
+		if slices.ContainsFunc(RedactedHeaders, func(redacted string) bool { return strings.EqualFold(redacted, name) }) {
+			flatten[name] = Redacted
+			continue
+		}
+		flatten[name] = strings.Join(values, ", ")
+	}
+	return flatten
+}
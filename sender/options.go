@@ -0,0 +1,44 @@
+package sender
+
+import (
+	"github.com/kanthorlabs/common/circuitbreaker"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+type Options struct {
+	CircuitBreaker    circuitbreaker.CircuitBreaker
+	CircuitBreakerKey func(r *entities.Request) string
+	Middlewares       []Middleware
+	UnixSockets       bool
+}
+
+type Option func(option *Options)
+
+// CircuitBreaker uses the given circuit breaker instead of the one that is created from the configuration
+func CircuitBreaker(cb circuitbreaker.CircuitBreaker) Option {
+	return func(option *Options) {
+		option.CircuitBreaker = cb
+	}
+}
+
+// CircuitBreakerKey groups requests into circuits by the returned key, the default key is the host of the request
+func CircuitBreakerKey(fn func(r *entities.Request) string) Option {
+	return func(option *Options) {
+		option.CircuitBreakerKey = fn
+	}
+}
+
+// Middlewares wraps the sender with the middlewares, the first middleware is the outermost one
+func Middlewares(middlewares ...Middleware) Option {
+	return func(option *Options) {
+		option.Middlewares = append(option.Middlewares, middlewares...)
+	}
+}
+
+// UnixSockets allows requests to unix:// and http+unix:// URIs, they are denied by default
+// because they reach local services that the egress policy could not protect
+func UnixSockets() Option {
+	return func(option *Options) {
+		option.UnixSockets = true
+	}
+}
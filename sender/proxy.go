@@ -0,0 +1,79 @@
+package sender
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/kanthorlabs/common/sender/config"
+)
+
+// proxy returns the proxy function of the transport that skips destinations of the no-proxy list
+func proxy(conf *config.Proxy) (func(r *http.Request) (*url.URL, error), error) {
+	uri, err := url.Parse(conf.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r *http.Request) (*url.URL, error) {
+		if NoProxy(conf.NoProxy, r.URL) {
+			return nil, nil
+		}
+		return uri, nil
+	}, nil
+}
+
+// NoProxy tells whether we should connect to the destination directly because it matches an entry of the list
+func NoProxy(entries []string, uri *url.URL) bool {
+	host := strings.ToLower(uri.Hostname())
+	port := uri.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[uri.Scheme]
+	}
+	addr, err := netip.ParseAddr(host)
+	isip := err == nil
+
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "*" {
+			return true
+		}
+
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			if isip && prefix.Contains(addr) {
+				return true
+			}
+			continue
+		}
+
+		// the port of the entry is optional, it must be matched when it is set
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+
+		if ip, err := netip.ParseAddr(entry); err == nil {
+			if isip && ip == addr {
+				return true
+			}
+			continue
+		}
+
+		// .example.com matches subdomains only while example.com matches itself and its subdomains
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(host, entry) {
+				return true
+			}
+			continue
+		}
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	server := httpserver()
+	defer server.Close()
+
+	// a proxy receives the absolute uri of plain HTTP requests, we answer them directly to see they went through it
+	proxied := make(chan string, 1)
+	proxyserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer proxyserver.Close()
+
+	t.Run("OK", func(st *testing.T) {
+		conf := *testconf
+		conf.Proxy = &config.Proxy{Uri: proxyserver.URL}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		uri := "http://partner.example.com/200"
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: uri})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusAccepted, res.Status)
+		require.Equal(st, uri, <-proxied)
+	})
+
+	t.Run("OK - no proxy", func(st *testing.T) {
+		conf := *testconf
+		conf.Proxy = &config.Proxy{Uri: proxyserver.URL, NoProxy: []string{"127.0.0.0/8"}}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+		require.Empty(st, proxied)
+	})
+}
+
+func TestNoProxy(t *testing.T) {
+	entries := []string{"10.0.0.0/8", "192.168.1.1", "internal.local", ".svc.cluster.local", "api.partner.com:8443"}
+
+	testcases := map[string]bool{
+		"http://10.1.2.3/":                    true,
+		"http://11.1.2.3/":                    false,
+		"http://192.168.1.1:8080/":            true,
+		"http://192.168.1.2/":                 false,
+		"https://internal.local/":             true,
+		"https://api.internal.local/":         true,
+		"https://notinternal.local/":          false,
+		"http://app.svc.cluster.local/":       true,
+		"http://svc.cluster.local/":           false,
+		"https://api.partner.com:8443/":       true,
+		"https://api.partner.com/":            false,
+		"http://[2001:db8::1]/":               false,
+		"https://INTERNAL.local/webhook/demo": true,
+	}
+
+	for uri, expected := range testcases {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		require.Equal(t, expected, NoProxy(entries, u), uri)
+	}
+
+	u, _ := url.Parse("https://example.com")
+	require.True(t, NoProxy([]string{"*"}, u))
+	require.False(t, NoProxy(nil, u))
+}
@@ -0,0 +1,135 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+var HeaderIdempotencyKey = "Idempotency-Key"
+
+// Retryable reports whether we could retry the request after the given number of attempts and how long we should wait before that.
+// The response is nil when the attempt failed at the transport level with the err.
+// The caller is responsible to stop retrying when its context is done
+func Retryable(conf *config.Retry, r *entities.Request, res *http.Response, err error, attempts int, elapsed time.Duration) (time.Duration, bool) {
+	if attempts > conf.Count {
+		return 0, false
+	}
+	// the reader is consumed by the previous attempt, we could send it again only when we could rewind it
+	if _, ok := r.Reader.(io.Seeker); r.Reader != nil && !ok {
+		return 0, false
+	}
+
+	wait := Backoff(conf, attempts)
+	if err != nil {
+		if !retryableError(err) {
+			return 0, false
+		}
+		// nothing was sent when the connection was refused, it is safe to retry any request
+		if !errors.Is(err, syscall.ECONNREFUSED) && !idempotent(conf, r) {
+			return 0, false
+		}
+	} else {
+		if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < http.StatusInternalServerError {
+			return 0, false
+		}
+		if !idempotent(conf, r) {
+			return 0, false
+		}
+
+		if after, ok := RetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			// the server asks us to wait longer than we are willing to, give up instead of retrying too early
+			if after > time.Millisecond*time.Duration(conf.WaitTimeCap()) {
+				return 0, false
+			}
+			wait = after
+		}
+	}
+
+	if conf.Budget > 0 && elapsed+wait > time.Millisecond*time.Duration(conf.Budget) {
+		return 0, false
+	}
+	return wait, true
+}
+
+// Backoff is the exponential backoff with full jitter (a random duration between zero and the capped exponential one)
+func Backoff(conf *config.Retry, attempts int) time.Duration {
+	return Exponential(conf.WaitTime, conf.WaitTimeCap(), 2, 1, attempts)
+}
+
+// Exponential grows the initial interval by the multiplier after every attempt up to the max interval, both are in milliseconds.
+// The jitter is the fraction of the interval that could be taken off randomly, 1 means full jitter
+func Exponential(initial, max int64, multiplier, jitter float64, attempts int) time.Duration {
+	interval := float64(initial) * math.Pow(multiplier, float64(attempts-1))
+	interval = math.Min(interval, float64(max))
+	interval -= interval * jitter * rand.Float64()
+
+	return time.Duration(interval * float64(time.Millisecond))
+}
+
+// RetryAfter parses the Retry-After header that is either the number of seconds or an HTTP date
+func RetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Second * time.Duration(seconds), true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		after := t.Sub(now)
+		if after < 0 {
+			after = 0
+		}
+		return after, true
+	}
+
+	return 0, false
+}
+
+func retryableError(err error) bool {
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+func idempotent(conf *config.Retry, r *entities.Request) bool {
+	if conf.NonIdempotent || r.Headers.Get(HeaderIdempotencyKey) != "" {
+		return true
+	}
+	return r.Method != http.MethodPost && r.Method != http.MethodPatch
+}
+
+// wait blocks until the duration is passed or the context is done
+func wait(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,167 @@
+package sender
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryable(t *testing.T) {
+	conf := &config.Retry{Count: 3, WaitTime: 100, MaxWaitTime: 1000, Budget: 10000}
+	get := &entities.Request{Method: http.MethodGet, Uri: testdata.Fake.Internet().URL()}
+	post := &entities.Request{Method: http.MethodPost, Uri: testdata.Fake.Internet().URL()}
+
+	response := func(status int, after string) *http.Response {
+		res := &http.Response{StatusCode: status, Header: make(http.Header)}
+		if after != "" {
+			res.Header.Set("Retry-After", after)
+		}
+		return res
+	}
+
+	t.Run("OK - server errors", func(st *testing.T) {
+		for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+			wait, retry := Retryable(conf, get, response(status, ""), nil, 1, 0)
+			require.True(st, retry, status)
+			require.LessOrEqual(st, wait, time.Millisecond*100)
+		}
+	})
+
+	t.Run("OK - retry after", func(st *testing.T) {
+		wait, retry := Retryable(conf, get, response(http.StatusServiceUnavailable, "1"), nil, 1, 0)
+		require.True(st, retry)
+		require.Equal(st, time.Second, wait)
+	})
+
+	t.Run("OK - network errors", func(st *testing.T) {
+		errs := []error{
+			&net.OpError{Op: "read", Err: syscall.ECONNRESET},
+			&timeouterror{},
+		}
+		for _, err := range errs {
+			_, retry := Retryable(conf, get, nil, fmt.Errorf("wrapped: %w", err), 1, 0)
+			require.True(st, retry, err.Error())
+		}
+	})
+
+	t.Run("OK - connection refused of non-idempotent methods", func(st *testing.T) {
+		_, retry := Retryable(conf, post, nil, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, 1, 0)
+		require.True(st, retry)
+	})
+
+	t.Run("OK - non-idempotent methods are allowed", func(st *testing.T) {
+		allowed := *conf
+		allowed.NonIdempotent = true
+		_, retry := Retryable(&allowed, post, response(http.StatusBadGateway, ""), nil, 1, 0)
+		require.True(st, retry)
+	})
+
+	t.Run("OK - idempotency key", func(st *testing.T) {
+		req := &entities.Request{Method: http.MethodPatch, Uri: post.Uri, Headers: http.Header{HeaderIdempotencyKey: []string{uuid.NewString()}}}
+		_, retry := Retryable(conf, req, response(http.StatusBadGateway, ""), nil, 1, 0)
+		require.True(st, retry)
+	})
+
+	t.Run("KO - client errors", func(st *testing.T) {
+		_, retry := Retryable(conf, get, response(http.StatusBadRequest, ""), nil, 1, 0)
+		require.False(st, retry)
+	})
+
+	t.Run("KO - non-idempotent methods", func(st *testing.T) {
+		_, retry := Retryable(conf, post, response(http.StatusServiceUnavailable, ""), nil, 1, 0)
+		require.False(st, retry)
+
+		_, retry = Retryable(conf, post, nil, &net.OpError{Op: "read", Err: syscall.ECONNRESET}, 1, 0)
+		require.False(st, retry)
+	})
+
+	t.Run("KO - unknown errors", func(st *testing.T) {
+		_, retry := Retryable(conf, get, nil, testdata.ErrGeneric, 1, 0)
+		require.False(st, retry)
+	})
+
+	t.Run("KO - count exceeded", func(st *testing.T) {
+		_, retry := Retryable(conf, get, response(http.StatusServiceUnavailable, ""), nil, conf.Count+1, 0)
+		require.False(st, retry)
+	})
+
+	t.Run("KO - budget exceeded", func(st *testing.T) {
+		_, retry := Retryable(conf, get, response(http.StatusServiceUnavailable, "1"), nil, 1, time.Millisecond*time.Duration(conf.Budget))
+		require.False(st, retry)
+	})
+
+	t.Run("KO - retry after is longer than the max wait time", func(st *testing.T) {
+		_, retry := Retryable(conf, get, response(http.StatusServiceUnavailable, "60"), nil, 1, 0)
+		require.False(st, retry)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		conf := &config.Retry{WaitTime: 100, MaxWaitTime: 1000}
+		for attempts := 1; attempts < 10; attempts++ {
+			wait := Backoff(conf, attempts)
+			require.GreaterOrEqual(st, wait, time.Duration(0))
+			require.LessOrEqual(st, wait, time.Millisecond*time.Duration(conf.MaxWaitTime))
+		}
+	})
+
+	t.Run("OK - exponential growth", func(st *testing.T) {
+		conf := &config.Retry{WaitTime: 100, MaxWaitTime: 100000}
+		var longest time.Duration
+		for i := 0; i < 100; i++ {
+			longest = max(longest, Backoff(conf, 6))
+		}
+		// 100 * 2^5 = 3200ms, it is hardly possible that 100 random waits are all below the base
+		require.Greater(st, longest, time.Millisecond*time.Duration(conf.WaitTime))
+		require.LessOrEqual(st, longest, time.Millisecond*3200)
+	})
+
+	t.Run("OK - no max wait time", func(st *testing.T) {
+		conf := &config.Retry{WaitTime: 100}
+		var longest time.Duration
+		for i := 0; i < 100; i++ {
+			longest = max(longest, Backoff(conf, 20))
+		}
+		require.Greater(st, longest, time.Millisecond*time.Duration(conf.WaitTime))
+		require.LessOrEqual(st, longest, time.Millisecond*time.Duration(config.MaxWaitTimeDefault))
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	after, ok := RetryAfter("120", now)
+	require.True(t, ok)
+	require.Equal(t, time.Minute*2, after)
+
+	after, ok = RetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, after)
+
+	after, ok = RetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Zero(t, after)
+
+	_, ok = RetryAfter("", now)
+	require.False(t, ok)
+	_, ok = RetryAfter("-1", now)
+	require.False(t, ok)
+	_, ok = RetryAfter(testdata.Fake.Lorem().Word(), now)
+	require.False(t, ok)
+}
+
+type timeouterror struct{}
+
+func (err *timeouterror) Error() string   { return "i/o timeout" }
+func (err *timeouterror) Timeout() bool   { return true }
+func (err *timeouterror) Temporary() bool { return true }
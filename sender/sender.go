@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/kanthorlabs/common/logging"
@@ -16,9 +17,10 @@ import (
 // Supported schemes are:
 // - http://
 // - https://
+// - unix:// and http+unix://, see UnixSchemes, they are denied unless the UnixSockets option is used
 // If the URI scheme is not supported, an error is returned.
-func New(conf *config.Config, logger logging.Logger) (Send, error) {
-	http, err := NewHttp(conf, logger)
+func New(conf *config.Config, logger logging.Logger, withOptions ...Option) (Send, error) {
+	http, err := NewHttp(conf, logger, withOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -29,8 +31,8 @@ func New(conf *config.Config, logger logging.Logger) (Send, error) {
 			return nil, errors.New("SENDER.URL.PARSE.ERROR")
 		}
 
-		// http & https
-		if strings.HasPrefix(uri.Scheme, "http") {
+		// http & https & unix domain sockets
+		if strings.HasPrefix(uri.Scheme, "http") || slices.Contains(UnixSchemes, uri.Scheme) {
 			return http(ctx, r)
 		}
 
@@ -84,6 +84,10 @@ func TestSender(t *testing.T) {
 }
 
 func httpserver() *httptest.Server {
+	return httptest.NewServer(httphandler())
+}
+
+func httphandler() http.Handler {
 	var handler = func(status int) func(w http.ResponseWriter, r *http.Request) {
 		return func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
@@ -99,11 +103,22 @@ func httpserver() *httptest.Server {
 	}
 
 	r := chi.NewRouter()
-	r.Get("/200", handler(http.StatusOK))
-	r.Post("/200", handler(http.StatusOK))
-	r.Put("/200", handler(http.StatusOK))
-	r.Patch("/200", handler(http.StatusOK))
-	r.Post("/500", handler(http.StatusInternalServerError))
+	for _, method := range entities.Methods {
+		r.MethodFunc(method, "/200", handler(http.StatusOK))
+	}
+	for _, method := range entities.Methods {
+		r.MethodFunc(method, "/500", handler(http.StatusInternalServerError))
+	}
+	r.Get("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	r.Get("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	})
+	r.Put("/429", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", r.URL.Query().Get("after"))
+		handler(http.StatusTooManyRequests)(w, r)
+	})
 	r.Post("/delay", func(w http.ResponseWriter, r *http.Request) {
 		ms, err := strconv.Atoi(r.URL.Query().Get("ms"))
 		if err != nil {
@@ -113,5 +128,5 @@ func httpserver() *httptest.Server {
 		time.Sleep(time.Millisecond * time.Duration(ms))
 		handler(http.StatusOK)(w, r)
 	})
-	return httptest.NewServer(r)
+	return r
 }
@@ -0,0 +1,45 @@
+package sender
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/kanthorlabs/common/sender/config"
+)
+
+// tlsconfig loads the certificate authorities and the client certificate of the configuration
+func tlsconfig(conf *config.Tls) (*tls.Config, error) {
+	c := &tls.Config{
+		MinVersion:         config.TlsVersions[conf.MinVersion],
+		ServerName:         conf.ServerName,
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+	}
+
+	if conf.Ca != "" {
+		pem, err := os.ReadFile(conf.Ca)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTlsMalformed, err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificate found in %s", ErrTlsMalformed, conf.Ca)
+		}
+		c.RootCAs = pool
+	}
+
+	if conf.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(conf.Cert, conf.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTlsMalformed, err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	return c, nil
+}
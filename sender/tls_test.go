@@ -0,0 +1,147 @@
+package sender
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTls(t *testing.T) {
+	t.Run("OK - custom ca", func(st *testing.T) {
+		server := httptest.NewTLSServer(httphandler())
+		defer server.Close()
+
+		conf := *testconf
+		conf.Tls = &config.Tls{Ca: writepem(st, "CERTIFICATE", server.Certificate().Raw), MinVersion: "1.2"}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+	})
+
+	t.Run("OK - mtls", func(st *testing.T) {
+		cert, key := clientcert(st)
+
+		server := httptest.NewUnstartedServer(httphandler())
+		pool := x509.NewCertPool()
+		pool.AddCert(cert)
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+		server.StartTLS()
+		defer server.Close()
+
+		conf := *testconf
+		conf.Retry.Count = 0
+		conf.Tls = &config.Tls{Ca: writepem(st, "CERTIFICATE", server.Certificate().Raw)}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		// the server rejects us without the client certificate
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, entities.StatusTransportError, res.Status)
+
+		conf.Tls.Cert = writepem(st, "CERTIFICATE", cert.Raw)
+		conf.Tls.Key = writepem(st, "PRIVATE KEY", key)
+		send, err = New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+	})
+
+	t.Run("OK - insecure skip verify", func(st *testing.T) {
+		server := httptest.NewTLSServer(httphandler())
+		defer server.Close()
+
+		conf := *testconf
+		conf.Tls = &config.Tls{InsecureSkipVerify: true}
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+	})
+
+	t.Run("KO - unknown authority", func(st *testing.T) {
+		server := httptest.NewTLSServer(httphandler())
+		defer server.Close()
+
+		conf := *testconf
+		conf.Retry.Count = 0
+		send, err := New(&conf, testify.Logger())
+		require.NoError(st, err)
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/200"})
+		require.NoError(st, err)
+		require.True(st, res.IsTlsError())
+	})
+
+	t.Run("KO - ca malformed", func(st *testing.T) {
+		conf := *testconf
+		conf.Tls = &config.Tls{Ca: writepem(st, "PRIVATE KEY", []byte("not a certificate"))}
+		_, err := New(&conf, testify.Logger())
+		require.ErrorIs(st, err, ErrTlsMalformed)
+
+		conf.Tls = &config.Tls{Ca: path.Join(st.TempDir(), "notfound.pem")}
+		_, err = New(&conf, testify.Logger())
+		require.ErrorIs(st, err, ErrTlsMalformed)
+	})
+
+	t.Run("KO - cert malformed", func(st *testing.T) {
+		conf := *testconf
+		conf.Tls = &config.Tls{Cert: path.Join(st.TempDir(), "cert.pem"), Key: path.Join(st.TempDir(), "key.pem")}
+		_, err := New(&conf, testify.Logger())
+		require.ErrorIs(st, err, ErrTlsMalformed)
+	})
+}
+
+func writepem(t *testing.T, kind string, data []byte) string {
+	filepath := path.Join(t.TempDir(), "file.pem")
+	require.NoError(t, os.WriteFile(filepath, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: data}), 0600))
+	return filepath
+}
+
+func clientcert(t *testing.T) (*x509.Certificate, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sender"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return cert, der
+}
@@ -0,0 +1,90 @@
+package sender
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+// tracer records the timing of an attempt through httptrace.
+// Callbacks could be called from different goroutines when the dialer races IPv4 and IPv6 addresses, so it is guarded by a mutex
+type tracer struct {
+	mu     sync.Mutex
+	begin  time.Time
+	dns    time.Time
+	conn   time.Time
+	tls    time.Time
+	timing entities.Timing
+}
+
+func newtracer(ctx context.Context) (context.Context, *tracer) {
+	t := &tracer{begin: time.Now()}
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.Dns += time.Since(t.dns)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.conn.IsZero() {
+				t.conn = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// only the first successful connection counts when many are raced
+			if err == nil && !t.conn.IsZero() {
+				t.timing.Connect += time.Since(t.conn)
+				t.conn = time.Time{}
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tls = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.Tls += time.Since(t.tls)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.Reused = info.Reused
+			if addr, ok := info.Conn.RemoteAddr().(*net.TCPAddr); ok {
+				t.timing.RemoteIp = addr.IP.String()
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.FirstByte = time.Since(t.begin)
+		},
+	}), t
+}
+
+// done returns the timing of the attempt
+func (t *tracer) done(protocol string) *entities.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timing := t.timing
+	timing.Total = time.Since(t.begin)
+	timing.Protocol = protocol
+	return &timing
+}
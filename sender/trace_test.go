@@ -0,0 +1,74 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace(t *testing.T) {
+	send, err := NewHttp(testconf, testify.Logger())
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		uri := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/200"
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: uri})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+
+		require.NotNil(st, res.Timing)
+		require.False(st, res.Timing.Reused)
+		require.Greater(st, res.Timing.Dns, time.Duration(0))
+		require.Greater(st, res.Timing.Connect, time.Duration(0))
+		require.Zero(st, res.Timing.Tls)
+		require.Greater(st, res.Timing.FirstByte, time.Duration(0))
+		require.GreaterOrEqual(st, res.Timing.Total, res.Timing.FirstByte)
+		require.Contains(st, []string{"127.0.0.1", "::1"}, res.Timing.RemoteIp)
+		require.Equal(st, "HTTP/1.1", res.Timing.Protocol)
+		require.Same(st, res.Timing, res.Attempts[0].Timing)
+		require.Equal(st, res.Timing.Total, res.Attempts[0].Duration)
+
+		// the connection is reused so there is no dns lookup or connection
+		res, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: uri})
+		require.NoError(st, err)
+		require.True(st, res.Timing.Reused)
+		require.Zero(st, res.Timing.Dns)
+		require.Zero(st, res.Timing.Connect)
+	})
+
+	t.Run("OK - retry", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodPut, Uri: server.URL + "/500"})
+		require.NoError(st, err)
+		require.Len(st, res.Attempts, testconf.Retry.Count+1)
+		for _, attempt := range res.Attempts {
+			require.NotNil(st, attempt.Timing)
+			require.Greater(st, attempt.Timing.FirstByte, time.Duration(0))
+		}
+		require.NotSame(st, res.Attempts[0].Timing, res.Attempts[1].Timing)
+	})
+
+	t.Run("OK - transport error", func(st *testing.T) {
+		server := httptest.NewTLSServer(http.NotFoundHandler())
+		defer server.Close()
+
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL})
+		require.NoError(st, err)
+		require.True(st, res.IsTlsError())
+		require.Greater(st, res.Timing.Tls, time.Duration(0))
+		require.Zero(st, res.Timing.FirstByte)
+		require.Empty(st, res.Timing.Protocol)
+	})
+}
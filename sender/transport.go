@@ -0,0 +1,86 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/kanthorlabs/common/sender/config"
+)
+
+var (
+	DialTimeout   = time.Second * 30
+	DialKeepAlive = time.Second * 30
+	RedirectMax   = 10
+)
+
+// transport builds the HTTP transport and the redirect policies of the configuration
+func transport(conf *config.Config) (*http.Transport, []any, error) {
+	var policy *EgressPolicy
+	if conf.Egress != nil {
+		var err error
+		if policy, err = NewEgressPolicy(conf.Egress); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: DialTimeout, KeepAlive: DialKeepAlive}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	redirects := []any{resty.RedirectPolicyFunc(redirectlimit), resty.FlexibleRedirectPolicy(RedirectMax), resty.RedirectPolicyFunc(unixredirect)}
+
+	if conf.Tls != nil {
+		c, err := tlsconfig(conf.Tls)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.TLSClientConfig = c
+	}
+	if conf.Proxy != nil {
+		fn, err := proxy(conf.Proxy)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.Proxy = fn
+	}
+
+	if policy != nil {
+		dialer.Control = policy.Control
+		// we would dial the proxy instead of the destination, so the policy could not protect us through a proxy
+		t.Proxy = nil
+		redirects = append(redirects, resty.RedirectPolicyFunc(policy.Redirect))
+	}
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// the egress policy denies unix domain sockets because their addresses are not IPs
+		socket, ok, err := unixbound(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	if t.Proxy != nil {
+		fn := t.Proxy
+		t.Proxy = func(r *http.Request) (*url.URL, error) {
+			if _, ok, _ := unixbound(r.Context(), r.URL.Host); ok {
+				return nil, nil
+			}
+			return fn(r)
+		}
+	}
+
+	return t, redirects, nil
+}
+
+// redirectlimit stops following redirects with a typed error, it must be placed before the resty policies
+func redirectlimit(req *http.Request, via []*http.Request) error {
+	if len(via) >= RedirectMax {
+		return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, RedirectMax)
+	}
+	return nil
+}
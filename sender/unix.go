@@ -0,0 +1,126 @@
+package sender
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var (
+	// UnixSchemes are schemes of requests that are sent over unix domain sockets.
+	// The socket path and the HTTP path are separated by a colon, for example: unix:///var/run/docker.sock:/containers/json
+	UnixSchemes = []string{"unix", "http+unix"}
+	// UnixHost is the Host header of requests that are sent over unix domain sockets
+	UnixHost = "localhost"
+)
+
+// the socket path is encoded into the host of the HTTP request so connections of different sockets are never shared.
+// The .invalid TLD is reserved, so it could never be a real host.
+// The host alone never makes us dial a socket, the socket must be bound to the context of the request by a unix URI as well
+var unixsuffix = ".unix.invalid"
+
+type unixkey struct{}
+
+// UnixSocket returns the socket path and the HTTP path of unix URIs, the socket path must be absolute
+func UnixSocket(uri *url.URL) (string, string, bool) {
+	if !slices.Contains(UnixSchemes, uri.Scheme) || uri.Host != "" {
+		return "", "", false
+	}
+
+	socket, path, found := strings.Cut(uri.Path, ":")
+	if !found || path == "" {
+		path = "/"
+	}
+	return socket, path, socket != ""
+}
+
+// unixtarget converts unix URIs to HTTP ones that could be dialed by the transport with the context that is bound to the socket,
+// other URIs are returned as they are
+func unixtarget(ctx context.Context, uri string) (context.Context, string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ctx, uri, false
+	}
+	socket, path, ok := UnixSocket(u)
+	if !ok {
+		return ctx, uri, false
+	}
+
+	target := &url.URL{Scheme: "http", Host: hex.EncodeToString([]byte(socket)) + unixsuffix, Path: path, RawQuery: u.RawQuery, Fragment: u.Fragment}
+	return context.WithValue(ctx, unixkey{}, socket), target.String(), true
+}
+
+// unixuri reports whether the uri is a unix URI
+func unixuri(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	_, _, ok := UnixSocket(u)
+	return ok
+}
+
+// unixforged reports whether the uri uses the internal host of unix domain sockets without being a unix URI
+func unixforged(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(u.Hostname()), unixsuffix)
+}
+
+// unixbound returns the socket path of the address only when the socket is bound to the context
+func unixbound(ctx context.Context, addr string) (string, bool, error) {
+	socket, ok := unixaddress(addr)
+	if !ok {
+		return "", false, nil
+	}
+	if bound, _ := ctx.Value(unixkey{}).(string); bound != socket {
+		return "", false, fmt.Errorf("%w: %s", ErrUnixTargetDenied, addr)
+	}
+	return socket, true, nil
+}
+
+// unixredirect refuses redirects onto unix domain sockets, only redirects within the socket of the previous hop are followed
+func unixredirect(req *http.Request, via []*http.Request) error {
+	if _, ok := unixaddress(req.URL.Host); !ok {
+		return nil
+	}
+	if len(via) > 0 && via[len(via)-1].URL.Host == req.URL.Host {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnixTargetDenied, req.URL.Host)
+}
+
+// unixorigin converts HTTP URIs of unix domain sockets back to unix URIs
+func unixorigin(u *url.URL) string {
+	socket, ok := unixaddress(u.Host)
+	if !ok {
+		return u.String()
+	}
+
+	origin := &url.URL{Scheme: UnixSchemes[0], Path: socket + ":" + u.Path, RawQuery: u.RawQuery, Fragment: u.Fragment}
+	return origin.String()
+}
+
+// unixaddress returns the socket path of the host with or without the port
+func unixaddress(addr string) (string, bool) {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	if !strings.HasSuffix(strings.ToLower(host), unixsuffix) {
+		return "", false
+	}
+
+	socket, err := hex.DecodeString(host[:len(host)-len(unixsuffix)])
+	if err != nil {
+		return "", false
+	}
+	return string(socket), true
+}
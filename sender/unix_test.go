@@ -0,0 +1,179 @@
+package sender
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+
+	"github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnix(t *testing.T) {
+	socket := unixserver(t)
+
+	send, err := New(testconf, testify.Logger(), UnixSockets())
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		for _, scheme := range UnixSchemes {
+			uri := fmt.Sprintf("%s://%s:/200?say=hello", scheme, socket)
+			res, err := send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: uri, Body: []byte("{}")})
+			require.NoError(st, err)
+			require.Equal(st, http.StatusOK, res.Status)
+			require.Equal(st, fmt.Sprintf("unix://%s:/200?say=hello", socket), res.Uri)
+
+			var data struct {
+				Headers http.Header `json:"req_headers"`
+				Body    string      `json:"req_body"`
+			}
+			require.NoError(st, json.Unmarshal(res.Body, &data))
+			require.Equal(st, "{}", data.Body)
+			require.Equal(st, testconf.Headers["client"], data.Headers.Get("client"))
+		}
+	})
+
+	t.Run("OK - retry", func(st *testing.T) {
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodPut, Uri: fmt.Sprintf("unix://%s:/500", socket)})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusInternalServerError, res.Status)
+		require.Len(st, res.Attempts, testconf.Retry.Count+1)
+	})
+
+	t.Run("KO - timeout", func(st *testing.T) {
+		uri := fmt.Sprintf("unix://%s:/delay?ms=%d", socket, testconf.Timeout)
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodPost, Uri: uri})
+		require.NoError(st, err)
+		require.Equal(st, entities.StatusTransportError, res.Status)
+		require.True(st, res.IsTimeout())
+	})
+
+	t.Run("KO - socket not found", func(st *testing.T) {
+		uri := fmt.Sprintf("unix://%s:/200", path.Join(st.TempDir(), "notfound.sock"))
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: uri})
+		require.NoError(st, err)
+		require.Equal(st, entities.StatusTransportError, res.Status)
+		require.True(st, res.IsTransportError())
+	})
+
+	t.Run("OK - redirect within the socket", func(st *testing.T) {
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: fmt.Sprintf("unix://%s:/redirect?to=/200", socket)})
+		require.NoError(st, err)
+		require.Equal(st, http.StatusOK, res.Status)
+		require.Equal(st, fmt.Sprintf("unix://%s:/200", socket), res.Uri)
+	})
+
+	t.Run("KO - forged host", func(st *testing.T) {
+		// warm up the connection pool so the forged request could not reuse a connection of the socket either
+		_, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: fmt.Sprintf("unix://%s:/200", socket)})
+		require.NoError(st, err)
+
+		uri := fmt.Sprintf("http://%s%s/200", hex.EncodeToString([]byte(socket)), unixsuffix)
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: uri})
+		require.ErrorIs(st, err, ErrUnixTargetDenied)
+		require.Nil(st, res)
+	})
+
+	t.Run("KO - redirect onto the socket", func(st *testing.T) {
+		server := httpserver()
+		defer server.Close()
+
+		to := url.QueryEscape(fmt.Sprintf("http://%s%s/200", hex.EncodeToString([]byte(socket)), unixsuffix))
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: server.URL + "/redirect?to=" + to})
+		require.ErrorIs(st, err, ErrUnixTargetDenied)
+		require.Nil(st, res)
+	})
+
+	t.Run("KO - redirect onto another socket", func(st *testing.T) {
+		another := unixserver(st)
+
+		to := url.QueryEscape(fmt.Sprintf("http://%s%s/200", hex.EncodeToString([]byte(another)), unixsuffix))
+		res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: fmt.Sprintf("unix://%s:/redirect?to=%s", socket, to)})
+		require.ErrorIs(st, err, ErrUnixTargetDenied)
+		require.Nil(st, res)
+	})
+
+	t.Run("KO - egress denied", func(st *testing.T) {
+		conf := *testconf
+		conf.Egress = &config.Egress{}
+		send, err := New(&conf, testify.Logger(), UnixSockets())
+		require.NoError(st, err)
+
+		_, err = send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: fmt.Sprintf("unix://%s:/200", socket)})
+		require.ErrorIs(st, err, ErrEgressDenied)
+	})
+
+	t.Run("KO - not allowed by default", func(st *testing.T) {
+		send, err := New(testconf, testify.Logger())
+		require.NoError(st, err)
+
+		for _, scheme := range UnixSchemes {
+			res, err := send(context.Background(), &entities.Request{Method: http.MethodGet, Uri: fmt.Sprintf("%s://%s:/200", scheme, socket)})
+			require.ErrorIs(st, err, ErrUnixTargetDenied)
+			require.Nil(st, res)
+		}
+	})
+}
+
+func TestUnixSocket(t *testing.T) {
+	testcases := map[string][]string{
+		"unix:///var/run/docker.sock:/containers/json": {"/var/run/docker.sock", "/containers/json"},
+		"http+unix:///tmp/agent.sock:/v1/health":       {"/tmp/agent.sock", "/v1/health"},
+		"unix:///var/run/docker.sock":                  {"/var/run/docker.sock", "/"},
+	}
+	for uri, expected := range testcases {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+
+		socket, path, ok := UnixSocket(u)
+		require.True(t, ok, uri)
+		require.Equal(t, expected, []string{socket, path}, uri)
+	}
+
+	for _, uri := range []string{"https://example.com/path", "unix://", "unix://:/path"} {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+
+		_, _, ok := UnixSocket(u)
+		require.False(t, ok, uri)
+	}
+
+	ctx, target, ok := unixtarget(context.Background(), "unix:///var/run/docker.sock:/containers/json?all=1")
+	require.True(t, ok)
+	u, err := url.Parse(target)
+	require.NoError(t, err)
+	require.Equal(t, "unix:///var/run/docker.sock:/containers/json?all=1", unixorigin(u))
+	require.False(t, unixforged("unix:///var/run/docker.sock:/containers/json?all=1"))
+	require.True(t, unixforged(target))
+
+	socket, ok, err := unixbound(ctx, u.Host)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "/var/run/docker.sock", socket)
+	_, _, err = unixbound(context.Background(), u.Host)
+	require.ErrorIs(t, err, ErrUnixTargetDenied)
+
+	_, target, ok = unixtarget(context.Background(), "https://example.com/path")
+	require.False(t, ok)
+	require.Equal(t, "https://example.com/path", target)
+}
+
+func unixserver(t *testing.T) string {
+	socket := path.Join(t.TempDir(), "sender.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := &http.Server{Handler: httphandler()}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return socket
+}
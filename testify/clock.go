@@ -0,0 +1,24 @@
+package testify
+
+import (
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+)
+
+// Clock returns a clock that is frozen at the given time
+func Clock(now time.Time) clock.Clock {
+	return &frozen{now: now}
+}
+
+type frozen struct {
+	now time.Time
+}
+
+func (c *frozen) Now() time.Time {
+	return c.now.UTC()
+}
+
+func (c *frozen) UnixMilli(msec int64) time.Time {
+	return time.UnixMilli(msec).UTC()
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/sender"
+	"github.com/kanthorlabs/common/sender/entities"
+)
+
+var (
+	ChallengeEventType      = "webhook.endpoint.challenge"
+	ChallengeMessageNs      = "msg"
+	ChallengeTokenEntropy   = 32
+	ChallengeTimeoutDefault = time.Second * 10
+)
+
+// ChallengeMessage is the body of the challenge event, the endpoint must echo the token back
+type ChallengeMessage struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+}
+
+// ChallengeAnswer is the body the endpoint responds to the challenge event
+type ChallengeAnswer struct {
+	Challenge string `json:"challenge"`
+}
+
+type ChallengeOptions struct {
+	Timeout time.Duration
+}
+
+type ChallengeOption func(option *ChallengeOptions)
+
+// ChallengeTimeout is the deadline the endpoint must answer the challenge within, the default value is 10 seconds
+func ChallengeTimeout(duration time.Duration) ChallengeOption {
+	return func(option *ChallengeOptions) {
+		option.Timeout = duration
+	}
+}
+
+// Challenge confirms that the endpoint is controlled by the receiver and it understands our signatures.
+// It sends a signed challenge event with a random token and expects the token to be echoed back within the deadline
+func Challenge(ctx context.Context, send sender.Send, wh Webhook, uri string, withOptions ...ChallengeOption) error {
+	options := &ChallengeOptions{Timeout: ChallengeTimeoutDefault}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	token, err := challengetoken()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(ChallengeMessage{Type: ChallengeEventType, Challenge: token})
+	if err != nil {
+		return err
+	}
+
+	headers, sealed, err := wh.Seal(idx.New(ChallengeMessageNs), string(body))
+	if err != nil {
+		return err
+	}
+
+	req := &entities.Request{
+		Method:  http.MethodPost,
+		Uri:     uri,
+		Headers: headers,
+		Body:    []byte(sealed),
+	}
+	// sealed bodies come with their own content type
+	if req.Headers.Get("Content-Type") == "" {
+		req.Headers.Set("Content-Type", "application/json")
+	}
+	req.Headers.Set(HeaderEventType, ChallengeEventType)
+
+	ctx, cancel := context.WithTimeout(ctx, options.Timeout)
+	defer cancel()
+
+	res, err := send(ctx, req)
+	if err != nil {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrChallengeTimeout
+	}
+	if !res.Ok() {
+		return fmt.Errorf("%w: %s", ErrChallengeFailed, res.StatusText())
+	}
+
+	var answer ChallengeAnswer
+	if err := json.Unmarshal(res.Body, &answer); err != nil {
+		return fmt.Errorf("%w: %s", ErrChallengeMismatch, err.Error())
+	}
+	if subtle.ConstantTimeCompare([]byte(answer.Challenge), []byte(token)) != 1 {
+		return ErrChallengeMismatch
+	}
+
+	return nil
+}
+
+// ChallengeResponder verifies incoming requests with the Middleware and answers challenge events automatically,
+// other events are handed to the next handler
+func ChallengeResponder(wh Webhook, withOptions ...MiddlewareOption) func(http.Handler) http.Handler {
+	verify := Middleware(wh, withOptions...)
+
+	return func(next http.Handler) http.Handler {
+		return verify(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the body has been read and restored by the middleware, so it is safe to read it again
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				Problem(w, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var msg ChallengeMessage
+			if json.Unmarshal(body, &msg) != nil || msg.Type != ChallengeEventType {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(ChallengeAnswer{Challenge: msg.Challenge})
+		}))
+	}
+}
+
+func challengetoken() (string, error) {
+	entropy := make([]byte, ChallengeTokenEntropy)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(entropy), nil
+}
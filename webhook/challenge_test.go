@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/sender"
+	senderconfig "github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallenge(t *testing.T) {
+	wh, err := New(keys)
+	require.NoError(t, err)
+
+	send, err := sender.New(senderconfig.Default, testify.Logger())
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		server := httptest.NewServer(ChallengeResponder(wh)(http.NotFoundHandler()))
+		defer server.Close()
+
+		require.NoError(st, Challenge(context.Background(), send, wh, server.URL))
+	})
+
+	t.Run("KO - mismatch error", func(st *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(ChallengeAnswer{Challenge: testdata.Fake.Lorem().Word()})
+		}))
+		defer server.Close()
+
+		require.ErrorIs(st, Challenge(context.Background(), send, wh, server.URL), ErrChallengeMismatch)
+	})
+
+	t.Run("KO - malformed answer error", func(st *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(testdata.Fake.Lorem().Sentence(5)))
+		}))
+		defer server.Close()
+
+		require.ErrorIs(st, Challenge(context.Background(), send, wh, server.URL), ErrChallengeMismatch)
+	})
+
+	t.Run("KO - failed error", func(st *testing.T) {
+		another, err := New([]string{"epsec_" + testdata.Fake.Lorem().Word()})
+		require.NoError(st, err)
+
+		// the receiver does not understand our signatures
+		server := httptest.NewServer(ChallengeResponder(another)(http.NotFoundHandler()))
+		defer server.Close()
+
+		require.ErrorIs(st, Challenge(context.Background(), send, wh, server.URL), ErrChallengeFailed)
+	})
+
+	t.Run("KO - timeout error", func(st *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+
+		err := Challenge(context.Background(), send, wh, server.URL, ChallengeTimeout(time.Millisecond*100))
+		require.ErrorIs(st, err, ErrChallengeTimeout)
+	})
+
+	t.Run("KO - send error", func(st *testing.T) {
+		send := func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			return nil, testdata.ErrGeneric
+		}
+		require.ErrorIs(st, Challenge(context.Background(), send, wh, testdata.Fake.Internet().URL()), testdata.ErrGeneric)
+	})
+}
+
+func TestChallengeResponder(t *testing.T) {
+	wh, err := New(keys)
+	require.NoError(t, err)
+
+	handler := ChallengeResponder(wh)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	t.Run("OK - other events", func(st *testing.T) {
+		body := `{"type":"order.created"}`
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header = wh.Headers(idx.New("msg"), body)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(st, http.StatusAccepted, w.Code)
+	})
+
+	t.Run("KO - unsigned challenge", func(st *testing.T) {
+		body := `{"type":"webhook.endpoint.challenge","challenge":"token"}`
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(st, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,62 @@
+package config
+
+import "github.com/kanthorlabs/common/validator"
+
+var Default = &Config{
+	ResponseSizeLimit: 4096,
+	Retry: Retry{
+		MaxAttempts:     8,
+		InitialInterval: 5000,
+		MaxInterval:     3600000,
+		Multiplier:      3,
+		Jitter:          0.2,
+		MaxAge:          86400000,
+	},
+}
+
+// Config is the configuration of the outbound webhook dispatcher
+type Config struct {
+	// ResponseSizeLimit how many bytes of the response body we keep in the delivery log
+	ResponseSizeLimit int   `json:"response_size_limit" yaml:"response_size_limit" mapstructure:"response_size_limit"`
+	Retry             Retry `json:"retry" yaml:"retry" mapstructure:"retry"`
+}
+
+func (conf *Config) Validate() error {
+	err := validator.Validate(
+		validator.NumberGreaterThanOrEqual("WEBHOOK.CONFIG.RESPONSE_SIZE_LIMIT", conf.ResponseSizeLimit, 0),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := conf.Retry.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Retry is an exponential backoff schedule with jitter.
+// The interval of the nth retry is InitialInterval * Multiplier^(n-1) but not greater than MaxInterval,
+// then it is reduced randomly by up to Jitter percent of its value.
+// Intervals and the maximum age are in milliseconds
+type Retry struct {
+	MaxAttempts     int     `json:"max_attempts" yaml:"max_attempts" mapstructure:"max_attempts"`
+	InitialInterval int64   `json:"initial_interval" yaml:"initial_interval" mapstructure:"initial_interval"`
+	MaxInterval     int64   `json:"max_interval" yaml:"max_interval" mapstructure:"max_interval"`
+	Multiplier      float64 `json:"multiplier" yaml:"multiplier" mapstructure:"multiplier"`
+	Jitter          float64 `json:"jitter" yaml:"jitter" mapstructure:"jitter"`
+	// MaxAge is the duration since the first attempt after that we give up on the delivery
+	MaxAge int64 `json:"max_age" yaml:"max_age" mapstructure:"max_age"`
+}
+
+func (conf *Retry) Validate() error {
+	return validator.Validate(
+		validator.NumberGreaterThanOrEqual("WEBHOOK.CONFIG.RETRY.MAX_ATTEMPTS", conf.MaxAttempts, 1),
+		validator.NumberGreaterThanOrEqual("WEBHOOK.CONFIG.RETRY.INITIAL_INTERVAL", conf.InitialInterval, 10),
+		validator.NumberGreaterThanOrEqual("WEBHOOK.CONFIG.RETRY.MAX_INTERVAL", conf.MaxInterval, conf.InitialInterval),
+		validator.NumberGreaterThanOrEqual("WEBHOOK.CONFIG.RETRY.MULTIPLIER", conf.Multiplier, 1.0),
+		validator.NumberInRange("WEBHOOK.CONFIG.RETRY.JITTER", conf.Jitter, 0.0, 1.0),
+		validator.NumberGreaterThanOrEqual("WEBHOOK.CONFIG.RETRY.MAX_AGE", conf.MaxAge, conf.InitialInterval),
+	)
+}
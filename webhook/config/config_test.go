@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		require.NoError(st, Default.Validate())
+	})
+
+	t.Run("KO", func(st *testing.T) {
+		conf := &Config{ResponseSizeLimit: -1}
+		require.ErrorContains(st, conf.Validate(), "WEBHOOK.CONFIG.RESPONSE_SIZE_LIMIT")
+	})
+
+	t.Run("KO - retry error", func(st *testing.T) {
+		conf := &Config{ResponseSizeLimit: testdata.Fake.IntBetween(0, 10000)}
+		require.ErrorContains(st, conf.Validate(), "WEBHOOK.CONFIG.RETRY")
+	})
+}
+
+func TestRetry(t *testing.T) {
+	conf := Default.Retry
+	conf.Jitter = 1.5
+	require.ErrorContains(t, conf.Validate(), "WEBHOOK.CONFIG.RETRY.JITTER")
+
+	conf = Default.Retry
+	conf.MaxInterval = conf.InitialInterval - 1
+	require.ErrorContains(t, conf.Validate(), "WEBHOOK.CONFIG.RETRY.MAX_INTERVAL")
+}
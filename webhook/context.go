@@ -0,0 +1,13 @@
+package webhook
+
+import "context"
+
+type ctxkey string
+
+var CtxMessage ctxkey = "webhook.message"
+
+// MessageFromContext returns the verified message that the middleware put into the request context
+func MessageFromContext(ctx context.Context) (*Message, bool) {
+	msg, ok := ctx.Value(CtxMessage).(*Message)
+	return msg, ok
+}
@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kanthorlabs/common/logging"
+	"github.com/kanthorlabs/common/sender"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/webhook/config"
+)
+
+var (
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Delivery is a message that is delivered to an endpoint and its delivery log.
+// Body is always the plain one, it is encrypted on every attempt when the webhook uses the Encryption option
+type Delivery struct {
+	Id       string      `json:"id"`
+	Uri      string      `json:"uri"`
+	Headers  http.Header `json:"headers"`
+	Body     string      `json:"body"`
+	Status   string      `json:"status"`
+	Attempts []Attempt   `json:"attempts"`
+}
+
+// Attempt is the record of a single request of a delivery.
+// Status is negative when the request could not reach the endpoint, the reason is in the Error field then
+type Attempt struct {
+	Number   int           `json:"number"`
+	At       time.Time     `json:"at"`
+	Status   int           `json:"status"`
+	Latency  time.Duration `json:"latency"`
+	Response string        `json:"response"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type DispatcherOptions struct {
+	OnAttempt func(ctx context.Context, delivery *Delivery, attempt *Attempt)
+	OnSuccess func(ctx context.Context, delivery *Delivery)
+	OnFailure func(ctx context.Context, delivery *Delivery)
+}
+
+type DispatcherOption func(option *DispatcherOptions)
+
+// OnAttempt is called after every attempt, it is the place to persist the delivery log
+func OnAttempt(fn func(ctx context.Context, delivery *Delivery, attempt *Attempt)) DispatcherOption {
+	return func(option *DispatcherOptions) {
+		option.OnAttempt = fn
+	}
+}
+
+// OnSuccess is called once the delivery is succeeded
+func OnSuccess(fn func(ctx context.Context, delivery *Delivery)) DispatcherOption {
+	return func(option *DispatcherOptions) {
+		option.OnSuccess = fn
+	}
+}
+
+// OnFailure is called once we give up on the delivery
+func OnFailure(fn func(ctx context.Context, delivery *Delivery)) DispatcherOption {
+	return func(option *DispatcherOptions) {
+		option.OnFailure = fn
+	}
+}
+
+// NewDispatcher creates a dispatcher that signs and sends webhook messages through the sender
+// and retries failed deliveries following the retry schedule of the configuration
+func NewDispatcher(conf *config.Config, send sender.Send, logger logging.Logger, withOptions ...DispatcherOption) (Dispatcher, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	options := &DispatcherOptions{}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	return &dispatcher{
+		conf:    conf,
+		send:    send,
+		logger:  logger.With("webhook", "dispatcher"),
+		options: options,
+	}, nil
+}
+
+type Dispatcher interface {
+	// Dispatch delivers the message until it is succeeded or the retry schedule is exhausted.
+	// Every attempt is signed again with the webhook so its timestamp is always fresh
+	Dispatch(ctx context.Context, wh Webhook, delivery *Delivery) error
+}
+
+type dispatcher struct {
+	conf    *config.Config
+	send    sender.Send
+	logger  logging.Logger
+	options *DispatcherOptions
+}
+
+func (d *dispatcher) Dispatch(ctx context.Context, wh Webhook, delivery *Delivery) error {
+	started := time.Now()
+
+	for {
+		attempt, err := d.attempt(ctx, wh, delivery)
+		if err != nil {
+			return d.fail(ctx, delivery, err)
+		}
+		if attempt.Status >= http.StatusOK && attempt.Status < http.StatusMultipleChoices {
+			delivery.Status = DeliveryStatusSucceeded
+			if d.options.OnSuccess != nil {
+				d.options.OnSuccess(ctx, delivery)
+			}
+			return nil
+		}
+
+		d.logger.Warnw("WEBHOOK.DISPATCHER.ATTEMPT.FAILED", "id", delivery.Id, "uri", delivery.Uri, "attempt", attempt.Number, "status", attempt.Status)
+
+		if attempt.Number >= d.conf.Retry.MaxAttempts {
+			return d.fail(ctx, delivery, ErrDeliveryAttemptsExhausted)
+		}
+		wait := Backoff(&d.conf.Retry, attempt.Number)
+		if time.Since(started)+wait > time.Millisecond*time.Duration(d.conf.Retry.MaxAge) {
+			return d.fail(ctx, delivery, ErrDeliveryMaxAgeExceeded)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return d.fail(ctx, delivery, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *dispatcher) attempt(ctx context.Context, wh Webhook, delivery *Delivery) (*Attempt, error) {
+	headers, body, err := wh.Seal(delivery.Id, delivery.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &entities.Request{
+		Method:  http.MethodPost,
+		Uri:     delivery.Uri,
+		Headers: make(http.Header),
+		Body:    []byte(body),
+	}
+	req.Headers.Set("Content-Type", "application/json")
+	for k, values := range delivery.Headers {
+		req.Headers[k] = values
+	}
+	// sealed headers go last so the content type of encrypted bodies could not be overridden
+	for k, values := range headers {
+		req.Headers[k] = values
+	}
+
+	attempt := Attempt{Number: len(delivery.Attempts) + 1, At: time.Now().UTC()}
+	res, err := d.send(ctx, req)
+	attempt.Latency = time.Since(attempt.At)
+	// some senders return the response along with the error, for example when the circuit is open, so we could retry later
+	if err != nil && res == nil {
+		// the request could not be sent at all (invalid request), there is no point to retry
+		attempt.Status = -1
+		attempt.Error = err.Error()
+		d.record(ctx, delivery, &attempt)
+		return nil, err
+	}
+
+	attempt.Status = res.Status
+	if res.Status < 0 {
+		attempt.Error = res.StatusText()
+	} else {
+		attempt.Response = truncate(string(res.Body), d.conf.ResponseSizeLimit)
+	}
+
+	d.record(ctx, delivery, &attempt)
+	return &attempt, nil
+}
+
+func (d *dispatcher) record(ctx context.Context, delivery *Delivery, attempt *Attempt) {
+	delivery.Attempts = append(delivery.Attempts, *attempt)
+	if d.options.OnAttempt != nil {
+		d.options.OnAttempt(ctx, delivery, attempt)
+	}
+}
+
+func (d *dispatcher) fail(ctx context.Context, delivery *Delivery, err error) error {
+	d.logger.Errorw("WEBHOOK.DISPATCHER.DELIVERY.FAILED", "id", delivery.Id, "uri", delivery.Uri, "attempts", len(delivery.Attempts), "error", err.Error())
+
+	delivery.Status = DeliveryStatusFailed
+	if d.options.OnFailure != nil {
+		d.options.OnFailure(ctx, delivery)
+	}
+	return err
+}
+
+// Backoff returns the interval we wait before the next attempt after the given number of attempts
+func Backoff(conf *config.Retry, attempts int) time.Duration {
+	return sender.Exponential(conf.InitialInterval, conf.MaxInterval, conf.Multiplier, conf.Jitter, attempts)
+}
+
+// truncate cuts the string at the limit in bytes without splitting a multi-byte character
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/sender"
+	senderconfig "github.com/kanthorlabs/common/sender/config"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/kanthorlabs/common/webhook/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDispatcher(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		_, err := NewDispatcher(testdispatcherconf, mocksend(http.StatusOK), testify.Logger())
+		require.NoError(st, err)
+	})
+
+	t.Run("KO - configuration error", func(st *testing.T) {
+		_, err := NewDispatcher(&config.Config{}, mocksend(http.StatusOK), testify.Logger())
+		require.ErrorContains(st, err, "WEBHOOK.CONFIG.")
+	})
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	wh, err := New(keys)
+	require.NoError(t, err)
+
+	t.Run("OK - verified by receiver", func(st *testing.T) {
+		server := httptest.NewServer(Middleware(wh)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strings.Repeat("ok", 100)))
+		})))
+		defer server.Close()
+
+		send, err := sender.New(senderconfig.Default, testify.Logger())
+		require.NoError(st, err)
+
+		var succeeded *Delivery
+		d, err := NewDispatcher(testdispatcherconf, send, testify.Logger(), OnSuccess(func(ctx context.Context, delivery *Delivery) {
+			succeeded = delivery
+		}))
+		require.NoError(st, err)
+
+		delivery := testdelivery(server.URL)
+		require.NoError(st, d.Dispatch(context.Background(), wh, delivery))
+		require.Equal(st, DeliveryStatusSucceeded, delivery.Status)
+		require.Same(st, delivery, succeeded)
+		require.Len(st, delivery.Attempts, 1)
+		require.Equal(st, http.StatusOK, delivery.Attempts[0].Status)
+		require.Len(st, delivery.Attempts[0].Response, testdispatcherconf.ResponseSizeLimit)
+	})
+
+	t.Run("OK - retry until success", func(st *testing.T) {
+		var count atomic.Int32
+		send := func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			if count.Add(1) < 3 {
+				return &entities.Response{Status: -1, Body: []byte("connection refused")}, nil
+			}
+			return &entities.Response{Status: http.StatusOK, Headers: make(http.Header), Uri: r.Uri}, nil
+		}
+
+		var attempts []int
+		d, err := NewDispatcher(testdispatcherconf, send, testify.Logger(), OnAttempt(func(ctx context.Context, delivery *Delivery, attempt *Attempt) {
+			attempts = append(attempts, attempt.Number)
+		}))
+		require.NoError(st, err)
+
+		delivery := testdelivery("http://localhost")
+		require.NoError(st, d.Dispatch(context.Background(), wh, delivery))
+		require.Equal(st, []int{1, 2, 3}, attempts)
+		require.Equal(st, "connection refused", delivery.Attempts[0].Error)
+	})
+
+	t.Run("KO - attempts exhausted error", func(st *testing.T) {
+		var failed bool
+		d, err := NewDispatcher(testdispatcherconf, mocksend(http.StatusInternalServerError), testify.Logger(), OnFailure(func(ctx context.Context, delivery *Delivery) {
+			failed = true
+		}))
+		require.NoError(st, err)
+
+		delivery := testdelivery("http://localhost")
+		require.ErrorIs(st, d.Dispatch(context.Background(), wh, delivery), ErrDeliveryAttemptsExhausted)
+		require.Equal(st, DeliveryStatusFailed, delivery.Status)
+		require.Len(st, delivery.Attempts, testdispatcherconf.Retry.MaxAttempts)
+		require.True(st, failed)
+	})
+
+	t.Run("KO - max age exceeded error", func(st *testing.T) {
+		conf := *testdispatcherconf
+		conf.Retry.MaxAge = conf.Retry.InitialInterval
+
+		d, err := NewDispatcher(&conf, mocksend(http.StatusBadGateway), testify.Logger())
+		require.NoError(st, err)
+
+		delivery := testdelivery("http://localhost")
+		require.ErrorIs(st, d.Dispatch(context.Background(), wh, delivery), ErrDeliveryMaxAgeExceeded)
+	})
+
+	t.Run("OK - retry when the response is returned along with the error", func(st *testing.T) {
+		var count atomic.Int32
+		send := func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			if count.Add(1) < 2 {
+				return &entities.Response{Status: entities.StatusCircuitOpen, Body: []byte(sender.ErrCircuitOpen.Error())}, sender.ErrCircuitOpen
+			}
+			return &entities.Response{Status: http.StatusOK, Headers: make(http.Header), Uri: r.Uri}, nil
+		}
+		d, err := NewDispatcher(testdispatcherconf, send, testify.Logger())
+		require.NoError(st, err)
+
+		delivery := testdelivery("http://localhost")
+		require.NoError(st, d.Dispatch(context.Background(), wh, delivery))
+		require.Len(st, delivery.Attempts, 2)
+		require.Equal(st, sender.ErrCircuitOpen.Error(), delivery.Attempts[0].Error)
+	})
+
+	t.Run("KO - send error", func(st *testing.T) {
+		send := func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+			return nil, testdata.ErrGeneric
+		}
+		d, err := NewDispatcher(testdispatcherconf, send, testify.Logger())
+		require.NoError(st, err)
+
+		delivery := testdelivery("http://localhost")
+		require.ErrorIs(st, d.Dispatch(context.Background(), wh, delivery), testdata.ErrGeneric)
+		require.Len(st, delivery.Attempts, 1)
+	})
+
+	t.Run("KO - context canceled error", func(st *testing.T) {
+		conf := *testdispatcherconf
+		conf.Retry.InitialInterval = 60000
+		conf.Retry.MaxInterval = 60000
+		conf.Retry.MaxAge = 600000
+
+		d, err := NewDispatcher(&conf, mocksend(http.StatusServiceUnavailable), testify.Logger())
+		require.NoError(st, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		require.ErrorIs(st, d.Dispatch(ctx, wh, testdelivery("http://localhost")), context.DeadlineExceeded)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	conf := config.Default.Retry
+	conf.Jitter = 0
+
+	require.Equal(t, time.Millisecond*time.Duration(conf.InitialInterval), Backoff(&conf, 1))
+	require.Equal(t, time.Millisecond*time.Duration(float64(conf.InitialInterval)*conf.Multiplier), Backoff(&conf, 2))
+	require.Equal(t, time.Millisecond*time.Duration(conf.MaxInterval), Backoff(&conf, 100))
+
+	conf.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		wait := Backoff(&conf, 1)
+		require.LessOrEqual(t, wait, time.Millisecond*time.Duration(conf.InitialInterval))
+		require.GreaterOrEqual(t, wait, time.Millisecond*time.Duration(conf.InitialInterval/2))
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "hello", truncate("hello", 10))
+	require.Equal(t, "hel", truncate("hello", 3))
+	// "é" takes 2 bytes, we must not cut it in half
+	require.Equal(t, "caf", truncate("café", 4))
+	require.Equal(t, "café", truncate("café", 5))
+	require.Equal(t, "", truncate("日本", 2))
+	require.Equal(t, "日", truncate("日本", 5))
+}
+
+var testdispatcherconf = &config.Config{
+	ResponseSizeLimit: 64,
+	Retry: config.Retry{
+		MaxAttempts:     3,
+		InitialInterval: 10,
+		MaxInterval:     20,
+		Multiplier:      2,
+		Jitter:          0.1,
+		MaxAge:          60000,
+	},
+}
+
+func testdelivery(uri string) *Delivery {
+	return &Delivery{
+		Id:      idx.New("msg"),
+		Uri:     uri,
+		Headers: http.Header{"X-Event-Type": []string{"testing.dispatcher"}},
+		Body:    `{"say":"hello"}`,
+	}
+}
+
+func mocksend(status int) sender.Send {
+	return func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+		return &entities.Response{Status: status, Headers: make(http.Header), Uri: r.Uri, Body: []byte(http.StatusText(status))}, nil
+	}
+}
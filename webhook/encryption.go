@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+var (
+	EncryptionKeyNs     = "epenc"
+	HeaderEncryption    = "Webhook-Encryption"
+	EncryptionAlgorithm = "aes-256-gcm"
+	EncryptionKeySize   = 32
+	// ContentTypeEncrypted is the content type of sealed bodies which are base64 encoded text, not the original payload
+	ContentTypeEncrypted = "text/plain; charset=utf-8"
+)
+
+// encryption seals message bodies with AES-256-GCM.
+// The sealed body is the base64 encoded nonce followed by the ciphertext,
+// and the message id with the algorithm is used as the additional data so a body could not be moved to another message
+type encryption struct {
+	aead cipher.AEAD
+}
+
+func newEncryption(key string) (*encryption, error) {
+	if !strings.HasPrefix(key, EncryptionKeyNs+"_") {
+		return nil, ErrEncryptionKeyMalformed
+	}
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(key, EncryptionKeyNs+"_"))
+	if err != nil || len(secret) != EncryptionKeySize {
+		return nil, ErrEncryptionKeyMalformed
+	}
+
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryption{aead: aead}, nil
+}
+
+func (enc *encryption) encrypt(id, body string) (string, error) {
+	nonce := make([]byte, enc.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := enc.aead.Seal(nonce, nonce, []byte(body), additional(id))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (enc *encryption) decrypt(id string, body []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(string(body))
+	if err != nil || len(sealed) < enc.aead.NonceSize() {
+		return nil, ErrMessageDecryptionFailed
+	}
+
+	nonce, ciphertext := sealed[:enc.aead.NonceSize()], sealed[enc.aead.NonceSize():]
+	plaintext, err := enc.aead.Open(nil, nonce, ciphertext, additional(id))
+	if err != nil {
+		return nil, ErrMessageDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+func additional(id string) []byte {
+	return []byte(id + "." + EncryptionAlgorithm)
+}
+
+// SignedBody returns the body part of the signed content.
+// The encryption algorithm is signed along with the sealed body so the Webhook-Encryption header
+// could not be stripped or changed without breaking the signature
+func SignedBody(algorithm, body string) string {
+	if algorithm == "" {
+		return body
+	}
+	return algorithm + "." + body
+}
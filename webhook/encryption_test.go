@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/sender/entities"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryption(t *testing.T) {
+	key, err := GenerateSecret(EncryptionKeyNs)
+	require.NoError(t, err)
+
+	wh, err := New(keys, Encryption(key))
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		body := testdata.Fake.Lorem().Sentence(10)
+		headers, sealed, err := wh.Seal(idx.New("msg"), body)
+		require.NoError(st, err)
+		require.NotEqual(st, body, sealed)
+		require.Equal(st, EncryptionAlgorithm, headers.Get(HeaderEncryption))
+		require.Equal(st, ContentTypeEncrypted, headers.Get("Content-Type"))
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(sealed))
+		req.Header = headers
+		require.NoError(st, wh.Verify(req))
+
+		data, err := io.ReadAll(req.Body)
+		require.NoError(st, err)
+		require.Equal(st, body, string(data))
+		require.Equal(st, int64(len(body)), req.ContentLength)
+	})
+
+	t.Run("OK - standard", func(st *testing.T) {
+		standard, err := New([]string{standardSecret}, Standard(), Encryption(key))
+		require.NoError(st, err)
+
+		body := testdata.Fake.Lorem().Sentence(10)
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(body))
+		require.NoError(st, standard.SignRequest(req, idx.New("msg"), time.Now()))
+		require.NoError(st, standard.Verify(req))
+
+		data, err := io.ReadAll(req.Body)
+		require.NoError(st, err)
+		require.Equal(st, body, string(data))
+	})
+
+	t.Run("OK - plain webhook ignores the encryption", func(st *testing.T) {
+		headers, sealed, err := wh.Seal(idx.New("msg"), testdata.Fake.Lorem().Sentence(10))
+		require.NoError(st, err)
+
+		plain, err := New(keys)
+		require.NoError(st, err)
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(sealed))
+		req.Header = headers
+		require.ErrorIs(st, plain.Verify(req), ErrMessageEncryptionUnsupported)
+	})
+
+	t.Run("KO - encryption required error", func(st *testing.T) {
+		body := testdata.Fake.Lorem().Sentence(10)
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(body))
+		req.Header = wh.Headers(idx.New("msg"), body)
+		require.ErrorIs(st, wh.Verify(req), ErrMessageEncryptionRequired)
+	})
+
+	t.Run("KO - unsupported algorithm error", func(st *testing.T) {
+		body := testdata.Fake.Lorem().Sentence(10)
+		headers := http.Header{HeaderEncryption: []string{"rot13"}}
+		wh.(*webhook).headers(headers, idx.New("msg"), time.Now(), body)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(body))
+		req.Header = headers
+		require.ErrorIs(st, wh.Verify(req), ErrMessageEncryptionUnsupported)
+	})
+
+	t.Run("KO - encryption header changed error", func(st *testing.T) {
+		headers, sealed, err := wh.Seal(idx.New("msg"), testdata.Fake.Lorem().Sentence(10))
+		require.NoError(st, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(sealed))
+		req.Header = headers
+		req.Header.Set(HeaderEncryption, "rot13")
+		require.ErrorIs(st, wh.Verify(req), ErrSignatureMismatch)
+	})
+
+	t.Run("KO - encryption header stripped error", func(st *testing.T) {
+		headers, sealed, err := wh.Seal(idx.New("msg"), testdata.Fake.Lorem().Sentence(10))
+		require.NoError(st, err)
+
+		plain, err := New(keys)
+		require.NoError(st, err)
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(sealed))
+		req.Header = headers
+		req.Header.Del(HeaderEncryption)
+		require.ErrorIs(st, plain.Verify(req), ErrSignatureMismatch)
+	})
+
+	t.Run("KO - decryption error", func(st *testing.T) {
+		another, err := GenerateSecret(EncryptionKeyNs)
+		require.NoError(st, err)
+		receiver, err := New(keys, Encryption(another))
+		require.NoError(st, err)
+
+		headers, sealed, err := wh.Seal(idx.New("msg"), testdata.Fake.Lorem().Sentence(10))
+		require.NoError(st, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(sealed))
+		req.Header = headers
+		require.ErrorIs(st, receiver.Verify(req), ErrMessageDecryptionFailed)
+	})
+
+	t.Run("KO - body moved to another message error", func(st *testing.T) {
+		_, sealed, err := wh.Seal(idx.New("msg"), testdata.Fake.Lorem().Sentence(10))
+		require.NoError(st, err)
+
+		headers := http.Header{HeaderEncryption: []string{EncryptionAlgorithm}}
+		wh.(*webhook).headers(headers, idx.New("msg"), time.Now(), sealed)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(sealed))
+		req.Header = headers
+		require.ErrorIs(st, wh.Verify(req), ErrMessageDecryptionFailed)
+	})
+}
+
+func TestEncryption_Key(t *testing.T) {
+	t.Run("KO - namespace error", func(st *testing.T) {
+		key, err := GenerateSecret(DefaultKeyNs)
+		require.NoError(st, err)
+
+		_, err = New(keys, Encryption(key))
+		require.ErrorIs(st, err, ErrEncryptionKeyMalformed)
+	})
+
+	t.Run("KO - size error", func(st *testing.T) {
+		key := idx.Build(EncryptionKeyNs, base64.StdEncoding.EncodeToString([]byte("too-short")))
+		_, err := New(keys, Encryption(key))
+		require.ErrorIs(st, err, ErrEncryptionKeyMalformed)
+	})
+}
+
+func TestEncryption_Dispatch(t *testing.T) {
+	key, err := GenerateSecret(EncryptionKeyNs)
+	require.NoError(t, err)
+	wh, err := New(keys, Encryption(key))
+	require.NoError(t, err)
+
+	body := `{"say":"hello"}`
+	send := func(ctx context.Context, r *entities.Request) (*entities.Response, error) {
+		req := httptest.NewRequest(r.Method, r.Uri, strings.NewReader(string(r.Body)))
+		req.Header = r.Headers
+		require.Equal(t, ContentTypeEncrypted, r.Headers.Get("Content-Type"))
+		if err := wh.Verify(req); err != nil {
+			return &entities.Response{Status: http.StatusUnauthorized, Headers: make(http.Header), Uri: r.Uri}, nil
+		}
+		data, _ := io.ReadAll(req.Body)
+		require.Equal(t, body, string(data))
+		return &entities.Response{Status: http.StatusOK, Headers: make(http.Header), Uri: r.Uri}, nil
+	}
+
+	d, err := NewDispatcher(testdispatcherconf, send, testify.Logger())
+	require.NoError(t, err)
+
+	delivery := testdelivery("http://localhost")
+	delivery.Body = body
+	require.NoError(t, d.Dispatch(context.Background(), wh, delivery))
+}
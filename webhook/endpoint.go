@@ -0,0 +1,249 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/validator"
+)
+
+var (
+	EndpointNs      = "ep"
+	HeaderEventType = "Webhook-Event-Type"
+	// EndpointSchemes are the schemes of endpoint uris, customers must never reach local services such as unix domain sockets
+	EndpointSchemes = []string{"http", "https"}
+)
+
+// Endpoint is a customer url that subscribes to some event types.
+// Event types support wildcards of the path.Match syntax, for example order.* or *
+type Endpoint struct {
+	Id         string   `json:"id" yaml:"id" mapstructure:"id"`
+	Uri        string   `json:"uri" yaml:"uri" mapstructure:"uri"`
+	EventTypes []string `json:"event_types" yaml:"event_types" mapstructure:"event_types"`
+	// Secrets are the keys of the endpoint, the first one is the newest one
+	Secrets  []string `json:"secrets" yaml:"secrets" mapstructure:"secrets"`
+	Standard bool     `json:"standard" yaml:"standard" mapstructure:"standard"`
+	Enabled  bool     `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	// EncryptionKey encrypts message bodies of the endpoint, every endpoint must have its own key. Bodies are not encrypted when it is empty
+	EncryptionKey string `json:"encryption_key,omitempty" yaml:"encryption_key,omitempty" mapstructure:"encryption_key"`
+}
+
+// NewEndpoint creates an enabled endpoint with a new id
+func NewEndpoint(uri string, eventTypes, secrets []string, withOptions ...Option) (*Endpoint, error) {
+	options := &Options{}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	ep := &Endpoint{
+		Id:            idx.New(EndpointNs),
+		Uri:           uri,
+		EventTypes:    eventTypes,
+		Secrets:       secrets,
+		Standard:      options.Standard,
+		Enabled:       true,
+		EncryptionKey: options.EncryptionKey,
+	}
+	if err := ep.Validate(withOptions...); err != nil {
+		return nil, err
+	}
+	return ep, nil
+}
+
+func (ep *Endpoint) Validate(withOptions ...Option) error {
+	err := validator.Validate(
+		validator.StringStartsWith("id", ep.Id, EndpointNs+"_"),
+		validator.StringUri("uri", ep.Uri),
+		func() error {
+			uri, err := url.Parse(ep.Uri)
+			if err != nil || !slices.Contains(EndpointSchemes, strings.ToLower(uri.Scheme)) {
+				return fmt.Errorf("%w: uri", ErrEndpointSchemeUnsupported)
+			}
+			return nil
+		},
+		validator.SliceRequired("event_types", ep.EventTypes),
+		validator.Slice(ep.EventTypes, func(i int, item *string) error {
+			if _, err := path.Match(*item, ""); err != nil || *item == "" {
+				return fmt.Errorf("%w: event_types[%d]", ErrEndpointEventTypeMalformed, i)
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = ep.Webhook(withOptions...)
+	return err
+}
+
+// Subscribes reports whether the endpoint is enabled and subscribes to the event type
+func (ep *Endpoint) Subscribes(eventType string) bool {
+	if !ep.Enabled {
+		return false
+	}
+	for _, pattern := range ep.EventTypes {
+		if matched, _ := path.Match(pattern, eventType); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook creates the webhook instance that signs messages with the secrets of the endpoint
+// and encrypts them with the encryption key of the endpoint, it takes precedence over the Encryption option
+func (ep *Endpoint) Webhook(withOptions ...Option) (Webhook, error) {
+	withOptions = slices.Clone(withOptions)
+	if ep.Standard {
+		withOptions = append(withOptions, Standard())
+	}
+	if ep.EncryptionKey != "" {
+		withOptions = append(withOptions, Encryption(ep.EncryptionKey))
+	}
+	return New(ep.Secrets, withOptions...)
+}
+
+// EndpointStore is where endpoints are persisted
+type EndpointStore interface {
+	Save(ctx context.Context, ep *Endpoint) error
+	Get(ctx context.Context, id string) (*Endpoint, error)
+	Delete(ctx context.Context, id string) error
+	// Subscribers returns enabled endpoints that subscribe to the event type
+	Subscribers(ctx context.Context, eventType string) ([]*Endpoint, error)
+}
+
+// NewMemoryEndpointStore creates an endpoint store that keeps endpoints in memory.
+// Endpoints are validated with the given options, they must be the ones the endpoints are created with
+func NewMemoryEndpointStore(withOptions ...Option) EndpointStore {
+	return &memoryendpoints{endpoints: make(map[string]Endpoint), options: withOptions}
+}
+
+type memoryendpoints struct {
+	mu        sync.RWMutex
+	endpoints map[string]Endpoint
+	options   []Option
+}
+
+func (store *memoryendpoints) Save(ctx context.Context, ep *Endpoint) error {
+	if err := ep.Validate(store.options...); err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.endpoints[ep.Id] = clone(ep)
+	return nil
+}
+
+func (store *memoryendpoints) Get(ctx context.Context, id string) (*Endpoint, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	ep, exist := store.endpoints[id]
+	if !exist {
+		return nil, ErrEndpointNotFound
+	}
+	cloned := clone(&ep)
+	return &cloned, nil
+}
+
+func (store *memoryendpoints) Delete(ctx context.Context, id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, exist := store.endpoints[id]; !exist {
+		return ErrEndpointNotFound
+	}
+	delete(store.endpoints, id)
+	return nil
+}
+
+func (store *memoryendpoints) Subscribers(ctx context.Context, eventType string) ([]*Endpoint, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	var endpoints []*Endpoint
+	for _, ep := range store.endpoints {
+		if ep.Subscribes(eventType) {
+			cloned := clone(&ep)
+			endpoints = append(endpoints, &cloned)
+		}
+	}
+	// ids are sortable by time, so endpoints are returned in the order they were created
+	slices.SortFunc(endpoints, func(a, b *Endpoint) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return endpoints, nil
+}
+
+func clone(ep *Endpoint) Endpoint {
+	cloned := *ep
+	cloned.EventTypes = slices.Clone(ep.EventTypes)
+	cloned.Secrets = slices.Clone(ep.Secrets)
+	return cloned
+}
+
+// Event is something happened that endpoints could subscribe to
+type Event struct {
+	Id   string
+	Type string
+	Body string
+}
+
+// Fanout is the delivery of an event to one endpoint.
+// Headers and Body are the sealed message that could be sent as it is, the Delivery keeps the plain body
+// so the Dispatcher seals it again with a fresh timestamp on every attempt
+type Fanout struct {
+	Endpoint *Endpoint
+	Webhook  Webhook
+	Delivery *Delivery
+	Headers  http.Header
+	Body     string
+}
+
+// FanoutEvent turns an event into sealed deliveries for every endpoint that subscribes to its type.
+// All deliveries share the event id so receivers could use it as the idempotency key
+func FanoutEvent(ctx context.Context, store EndpointStore, event *Event, withOptions ...Option) ([]Fanout, error) {
+	err := validator.Validate(
+		validator.StringRequired("event.id", event.Id),
+		validator.StringRequired("event.type", event.Type),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := store.Subscribers(ctx, event.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	fanouts := make([]Fanout, 0, len(endpoints))
+	for _, ep := range endpoints {
+		wh, err := ep.Webhook(withOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, ep.Id)
+		}
+
+		headers, body, err := wh.Seal(event.Id, event.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, ep.Id)
+		}
+		headers.Set(HeaderEventType, event.Type)
+
+		fanouts = append(fanouts, Fanout{
+			Endpoint: ep,
+			Webhook:  wh,
+			Delivery: &Delivery{Id: event.Id, Uri: ep.Uri, Headers: http.Header{HeaderEventType: []string{event.Type}}, Body: event.Body},
+			Headers:  headers,
+			Body:     body,
+		})
+	}
+	return fanouts, nil
+}
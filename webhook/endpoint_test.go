@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEndpoint(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		ep, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"order.*"}, keys)
+		require.NoError(st, err)
+		require.True(st, strings.HasPrefix(ep.Id, EndpointNs+"_"))
+		require.True(st, ep.Enabled)
+		require.False(st, ep.Standard)
+	})
+
+	t.Run("OK - standard", func(st *testing.T) {
+		ep, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"*"}, []string{standardSecret}, Standard())
+		require.NoError(st, err)
+		require.True(st, ep.Standard)
+	})
+
+	t.Run("KO - uri error", func(st *testing.T) {
+		_, err := NewEndpoint(testdata.Fake.Internet().Email(), []string{"*"}, keys)
+		require.ErrorContains(st, err, "uri")
+	})
+
+	t.Run("KO - scheme unsupported error", func(st *testing.T) {
+		for _, uri := range []string{"unix:///var/run/docker.sock:/containers/json", "http+unix:///var/run/docker.sock:/info", "ftp://example.com/webhook"} {
+			_, err := NewEndpoint(uri, []string{"*"}, keys)
+			require.ErrorIs(st, err, ErrEndpointSchemeUnsupported, uri)
+		}
+	})
+
+	t.Run("KO - event type malformed error", func(st *testing.T) {
+		_, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"order.["}, keys)
+		require.ErrorIs(st, err, ErrEndpointEventTypeMalformed)
+	})
+
+	t.Run("KO - secrets error", func(st *testing.T) {
+		_, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"*"}, []string{standardSecret})
+		require.ErrorContains(st, err, "keys[0]")
+	})
+}
+
+func TestEndpoint_Subscribes(t *testing.T) {
+	ep, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"order.*", "user.created"}, keys)
+	require.NoError(t, err)
+
+	require.True(t, ep.Subscribes("order.created"))
+	require.True(t, ep.Subscribes("user.created"))
+	require.False(t, ep.Subscribes("user.deleted"))
+	require.False(t, ep.Subscribes("order"))
+
+	ep.Enabled = false
+	require.False(t, ep.Subscribes("order.created"))
+}
+
+func TestMemoryEndpointStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryEndpointStore()
+
+	ep, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"order.*"}, keys)
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		require.NoError(st, store.Save(ctx, ep))
+
+		found, err := store.Get(ctx, ep.Id)
+		require.NoError(st, err)
+		require.Equal(st, ep, found)
+
+		// modifying the returned endpoint must not affect the stored one
+		found.Secrets[0] = "epsec_modified"
+		again, err := store.Get(ctx, ep.Id)
+		require.NoError(st, err)
+		require.Equal(st, ep.Secrets, again.Secrets)
+	})
+
+	t.Run("KO - validation error", func(st *testing.T) {
+		require.Error(st, store.Save(ctx, &Endpoint{Id: idx.New(EndpointNs)}))
+	})
+
+	t.Run("OK - custom key namespace", func(st *testing.T) {
+		ns := testdata.Fake.RandomStringWithLength(5)
+		custom, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"*"}, []string{idx.Build(ns, utils.RandomString(128))}, KeyNamespace(ns))
+		require.NoError(st, err)
+
+		require.Error(st, store.Save(ctx, custom))
+		require.NoError(st, NewMemoryEndpointStore(KeyNamespace(ns)).Save(ctx, custom))
+	})
+
+	t.Run("KO - not found error", func(st *testing.T) {
+		_, err := store.Get(ctx, idx.New(EndpointNs))
+		require.ErrorIs(st, err, ErrEndpointNotFound)
+		require.ErrorIs(st, store.Delete(ctx, idx.New(EndpointNs)), ErrEndpointNotFound)
+	})
+
+	t.Run("OK - delete", func(st *testing.T) {
+		require.NoError(st, store.Delete(ctx, ep.Id))
+		_, err := store.Get(ctx, ep.Id)
+		require.ErrorIs(st, err, ErrEndpointNotFound)
+	})
+}
+
+func TestFanoutEvent(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryEndpointStore()
+
+	orders, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"order.*"}, keys)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(ctx, orders))
+
+	all, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"*"}, []string{standardSecret}, Standard())
+	require.NoError(t, err)
+	require.NoError(t, store.Save(ctx, all))
+
+	disabled, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"*"}, keys)
+	require.NoError(t, err)
+	disabled.Enabled = false
+	require.NoError(t, store.Save(ctx, disabled))
+
+	t.Run("OK", func(st *testing.T) {
+		event := &Event{Id: idx.New("msg"), Type: "order.created", Body: `{"id":1}`}
+
+		fanouts, err := FanoutEvent(ctx, store, event)
+		require.NoError(st, err)
+		require.Len(st, fanouts, 2)
+
+		for _, fanout := range fanouts {
+			require.Equal(st, event.Id, fanout.Delivery.Id)
+			require.Equal(st, fanout.Endpoint.Uri, fanout.Delivery.Uri)
+			require.Equal(st, event.Type, fanout.Delivery.Headers.Get(HeaderEventType))
+			require.Equal(st, event.Body, fanout.Delivery.Body)
+			require.Equal(st, event.Type, fanout.Headers.Get(HeaderEventType))
+
+			req := httptest.NewRequest(http.MethodPost, fanout.Endpoint.Uri, strings.NewReader(fanout.Body))
+			req.Header = fanout.Headers
+			require.NoError(st, fanout.Webhook.Verify(req))
+		}
+	})
+
+	t.Run("OK - encryption key per endpoint", func(st *testing.T) {
+		store := NewMemoryEndpointStore()
+		for i := 0; i < 2; i++ {
+			key, err := GenerateSecret(EncryptionKeyNs)
+			require.NoError(st, err)
+
+			ep, err := NewEndpoint(testdata.Fake.Internet().URL(), []string{"*"}, []string{standardSecret}, Standard(), Encryption(key))
+			require.NoError(st, err)
+			require.Equal(st, key, ep.EncryptionKey)
+			require.NoError(st, store.Save(ctx, ep))
+		}
+
+		event := &Event{Id: idx.New("msg"), Type: "order.created", Body: `{"id":1}`}
+		fanouts, err := FanoutEvent(ctx, store, event)
+		require.NoError(st, err)
+		require.Len(st, fanouts, 2)
+		require.NotEqual(st, fanouts[0].Body, fanouts[1].Body)
+
+		for i, fanout := range fanouts {
+			another := fanouts[1-i].Endpoint.EncryptionKey
+			require.NotEqual(st, fanout.Endpoint.EncryptionKey, another)
+
+			require.NotEqual(st, event.Body, fanout.Body)
+			require.Equal(st, EncryptionAlgorithm, fanout.Headers.Get(HeaderEncryption))
+			require.Equal(st, ContentTypeEncrypted, fanout.Headers.Get("Content-Type"))
+
+			req := httptest.NewRequest(http.MethodPost, fanout.Endpoint.Uri, strings.NewReader(fanout.Body))
+			req.Header = fanout.Headers.Clone()
+			require.NoError(st, fanout.Webhook.Verify(req))
+			data, err := io.ReadAll(req.Body)
+			require.NoError(st, err)
+			require.Equal(st, event.Body, string(data))
+
+			// the key of one endpoint could not open messages of another one
+			other, err := New([]string{standardSecret}, Standard(), Encryption(another))
+			require.NoError(st, err)
+			req = httptest.NewRequest(http.MethodPost, fanout.Endpoint.Uri, strings.NewReader(fanout.Body))
+			req.Header = fanout.Headers.Clone()
+			require.ErrorIs(st, other.Verify(req), ErrMessageDecryptionFailed)
+		}
+	})
+
+	t.Run("OK - no subscribers", func(st *testing.T) {
+		fanouts, err := FanoutEvent(ctx, store, &Event{Id: idx.New("msg"), Type: "user.created"})
+		require.NoError(st, err)
+		require.Len(st, fanouts, 1)
+		require.Equal(st, all.Id, fanouts[0].Endpoint.Id)
+	})
+
+	t.Run("KO - event error", func(st *testing.T) {
+		_, err := FanoutEvent(ctx, store, &Event{Id: idx.New("msg")})
+		require.ErrorContains(st, err, "event.type")
+	})
+}
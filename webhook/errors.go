@@ -8,4 +8,24 @@ var (
 	ErrMessageTimestampMalformed          = errors.New("WEBHOOK.MESSAGE.TIMESTAMP_MALFORMED.ERROR")
 	ErrMessageTimestampTooOld             = errors.New("WEBHOOK.MESSAGE.TIMESTAMP_TOO_OLD.ERROR")
 	ErrMessageTimestampTooNew             = errors.New("WEBHOOK.MESSAGE.TIMESTAMP_TOO_NEW.ERROR")
+	ErrMessageBodyTooLarge                = errors.New("WEBHOOK.MESSAGE.BODY_TOO_LARGE.ERROR")
+	ErrMessageBodyRead                    = errors.New("WEBHOOK.MESSAGE.BODY_READ.ERROR")
+	ErrMessageIdEmpty                     = errors.New("WEBHOOK.MESSAGE.ID_EMPTY.ERROR")
+	ErrMessageReplayed                    = errors.New("WEBHOOK.MESSAGE.REPLAYED.ERROR")
+	ErrReplayStoreUnavailable             = errors.New("WEBHOOK.REPLAY_STORE.UNAVAILABLE.ERROR")
+	ErrVerificationInternal               = errors.New("WEBHOOK.VERIFICATION.INTERNAL.ERROR")
+	ErrDeliveryAttemptsExhausted          = errors.New("WEBHOOK.DELIVERY.ATTEMPTS_EXHAUSTED.ERROR")
+	ErrDeliveryMaxAgeExceeded             = errors.New("WEBHOOK.DELIVERY.MAX_AGE_EXCEEDED.ERROR")
+	ErrEndpointNotFound                   = errors.New("WEBHOOK.ENDPOINT.NOT_FOUND.ERROR")
+	ErrEndpointEventTypeMalformed         = errors.New("WEBHOOK.ENDPOINT.EVENT_TYPE_MALFORMED.ERROR")
+	ErrEndpointSchemeUnsupported          = errors.New("WEBHOOK.ENDPOINT.SCHEME_UNSUPPORTED.ERROR")
+	ErrSecretRetainOutOfRange             = errors.New("WEBHOOK.SECRET.RETAIN_OUT_OF_RANGE.ERROR")
+	ErrSecretSetScan                      = errors.New("WEBHOOK.SECRET_SET.SCAN.ERROR")
+	ErrChallengeTimeout                   = errors.New("WEBHOOK.CHALLENGE.TIMEOUT.ERROR")
+	ErrChallengeFailed                    = errors.New("WEBHOOK.CHALLENGE.FAILED.ERROR")
+	ErrChallengeMismatch                  = errors.New("WEBHOOK.CHALLENGE.MISMATCH.ERROR")
+	ErrEncryptionKeyMalformed             = errors.New("WEBHOOK.ENCRYPTION.KEY_MALFORMED.ERROR")
+	ErrMessageEncryptionRequired          = errors.New("WEBHOOK.MESSAGE.ENCRYPTION_REQUIRED.ERROR")
+	ErrMessageEncryptionUnsupported       = errors.New("WEBHOOK.MESSAGE.ENCRYPTION_UNSUPPORTED.ERROR")
+	ErrMessageDecryptionFailed            = errors.New("WEBHOOK.MESSAGE.DECRYPTION_FAILED.ERROR")
 )
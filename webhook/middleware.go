@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+var (
+	MaxBodySizeDefault int64 = 1024 * 1024
+	ContentTypeProblem       = "application/problem+json"
+)
+
+type MiddlewareOptions struct {
+	MaxBodySize   int64
+	VerifyOptions []VerifyOption
+}
+
+type MiddlewareOption func(option *MiddlewareOptions)
+
+// MaxBodySize limits the size of the request body the middleware reads, the default value is 1MB
+func MaxBodySize(size int64) MiddlewareOption {
+	return func(option *MiddlewareOptions) {
+		option.MaxBodySize = size
+	}
+}
+
+// Verification passes the verify options to Webhook.Verify
+func Verification(withOptions ...VerifyOption) MiddlewareOption {
+	return func(option *MiddlewareOptions) {
+		option.VerifyOptions = append(option.VerifyOptions, withOptions...)
+	}
+}
+
+// Middleware verifies incoming webhook requests before handing them to the next handler.
+// It works with both net/http and chi because it follows the func(http.Handler) http.Handler signature.
+// The body is restored for the next handler, and the verified message is put into the request context
+// that could be retrieved with MessageFromContext. Failed requests are rejected with a problem response (RFC 9457)
+func Middleware(wh Webhook, withOptions ...MiddlewareOption) func(http.Handler) http.Handler {
+	options := &MiddlewareOptions{MaxBodySize: MaxBodySizeDefault}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, options.MaxBodySize)
+
+			if err := wh.Verify(r, options.VerifyOptions...); err != nil {
+				Problem(w, err)
+				return
+			}
+
+			msg, err := wh.Message(r)
+			if err != nil {
+				Problem(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), CtxMessage, msg)))
+		})
+	}
+}
+
+// Status maps the errors of the webhook package to HTTP status codes
+func Status(err error) (int, error) {
+	var maxbytes *http.MaxBytesError
+	if errors.As(err, &maxbytes) {
+		return http.StatusRequestEntityTooLarge, ErrMessageBodyTooLarge
+	}
+
+	switch {
+	case errors.Is(err, ErrMessageBodyTooLarge):
+		return http.StatusRequestEntityTooLarge, err
+	case errors.Is(err, ErrMessageTimestampMalformed),
+		errors.Is(err, ErrMessageIdEmpty),
+		errors.Is(err, ErrMessageEncryptionRequired),
+		errors.Is(err, ErrMessageDecryptionFailed):
+		return http.StatusBadRequest, err
+	case errors.Is(err, ErrMessageEncryptionUnsupported):
+		return http.StatusUnsupportedMediaType, err
+	case errors.Is(err, ErrMessageReplayed):
+		return http.StatusConflict, err
+	case errors.Is(err, ErrSignatureMismatch),
+		errors.Is(err, ErrMessageTimestampTooOld),
+		errors.Is(err, ErrMessageTimestampTooNew):
+		return http.StatusUnauthorized, err
+	case errors.Is(err, ErrMessageBodyRead):
+		return http.StatusBadRequest, ErrMessageBodyRead
+	case errors.Is(err, ErrReplayStoreUnavailable):
+		return http.StatusServiceUnavailable, ErrReplayStoreUnavailable
+	}
+
+	// never expose unknown errors, they are our problems instead of the problems of the sender
+	return http.StatusInternalServerError, ErrVerificationInternal
+}
+
+// Problem writes the error as a problem response
+func Problem(w http.ResponseWriter, err error) {
+	status, err := Status(err)
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"type":   "about:blank",
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": err.Error(),
+	})
+}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	wh, err := New(keys)
+	require.NoError(t, err)
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg, ok := MessageFromContext(r.Context())
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set(HeaderId, msg.Id)
+		w.Header().Set(HeaderTimestamp, fmt.Sprintf("%d", msg.Timestamp.UnixMilli()))
+		w.Write(body)
+	})
+
+	body := utils.Stringify(map[string]any{"type": "testing.middleware", "say": testdata.Fake.Lorem().Sentence(5)})
+
+	t.Run("OK - net/http", func(st *testing.T) {
+		req, id, ts := signed(st, wh, body)
+
+		w := httptest.NewRecorder()
+		Middleware(wh)(echo).ServeHTTP(w, req)
+
+		require.Equal(st, http.StatusOK, w.Code)
+		require.Equal(st, body, w.Body.String())
+		require.Equal(st, id, w.Header().Get(HeaderId))
+		require.Equal(st, ts, w.Header().Get(HeaderTimestamp))
+	})
+
+	t.Run("OK - chi", func(st *testing.T) {
+		r := chi.NewRouter()
+		r.Use(Middleware(wh, Verification(TimestampToleranceDuration(time.Hour))))
+		r.Post("/webhook/demo", echo)
+
+		req, _, _ := signed(st, wh, body)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(st, http.StatusOK, w.Code)
+		require.Equal(st, body, w.Body.String())
+	})
+
+	t.Run("KO - body too large", func(st *testing.T) {
+		req, _, _ := signed(st, wh, body)
+
+		w := httptest.NewRecorder()
+		Middleware(wh, MaxBodySize(int64(len(body)-1)))(echo).ServeHTTP(w, req)
+
+		require.Equal(st, http.StatusRequestEntityTooLarge, w.Code)
+		require.Equal(st, ContentTypeProblem, w.Header().Get("Content-Type"))
+		require.Contains(st, w.Body.String(), ErrMessageBodyTooLarge.Error())
+	})
+
+	t.Run("KO - signature mismatch", func(st *testing.T) {
+		req, _, _ := signed(st, wh, body)
+		req.Body = io.NopCloser(strings.NewReader(body + " "))
+
+		w := httptest.NewRecorder()
+		Middleware(wh)(echo).ServeHTTP(w, req)
+
+		require.Equal(st, http.StatusUnauthorized, w.Code)
+
+		var problem map[string]any
+		require.NoError(st, json.Unmarshal(w.Body.Bytes(), &problem))
+		require.Equal(st, float64(http.StatusUnauthorized), problem["status"])
+		require.Equal(st, ErrSignatureMismatch.Error(), problem["detail"])
+	})
+
+	t.Run("KO - timestamp malformed", func(st *testing.T) {
+		req, _, _ := signed(st, wh, body)
+		req.Header.Set(HeaderTimestamp, "xxx")
+
+		w := httptest.NewRecorder()
+		Middleware(wh)(echo).ServeHTTP(w, req)
+
+		require.Equal(st, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	testcases := map[error]int{
+		ErrMessageBodyTooLarge:          http.StatusRequestEntityTooLarge,
+		ErrMessageTimestampMalformed:    http.StatusBadRequest,
+		ErrMessageTimestampTooOld:       http.StatusUnauthorized,
+		ErrMessageTimestampTooNew:       http.StatusUnauthorized,
+		ErrSignatureMismatch:            http.StatusUnauthorized,
+		ErrMessageIdEmpty:               http.StatusBadRequest,
+		ErrMessageReplayed:              http.StatusConflict,
+		ErrMessageEncryptionRequired:    http.StatusBadRequest,
+		ErrMessageDecryptionFailed:      http.StatusBadRequest,
+		ErrMessageEncryptionUnsupported: http.StatusUnsupportedMediaType,
+		ErrMessageBodyRead:              http.StatusBadRequest,
+		ErrReplayStoreUnavailable:       http.StatusServiceUnavailable,
+		testdata.ErrGeneric:             http.StatusInternalServerError,
+	}
+
+	for err, expected := range testcases {
+		status, _ := Status(err)
+		require.Equal(t, expected, status, err.Error())
+	}
+
+	_, err := Status(testdata.ErrGeneric)
+	require.ErrorIs(t, err, ErrVerificationInternal)
+}
+
+func signed(t *testing.T, wh Webhook, body string) (*http.Request, string, string) {
+	id := idx.New("msg")
+	ts := fmt.Sprintf("%d", time.Now().UnixMilli())
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(body))
+	req.Header.Set(HeaderId, id)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, strings.Join(wh.Sign(id, ts, body), " "))
+	return req, id, ts
+}
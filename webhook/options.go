@@ -1,6 +1,10 @@
 package webhook
 
-import "time"
+import (
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+)
 
 var (
 	DefaultKeyNs             = "epsec"
@@ -14,6 +18,10 @@ var (
 
 type Options struct {
 	KeyNamespace string
+	Standard     bool
+	Clock        clock.Clock
+	// EncryptionKey is the key of the endpoint to encrypt and decrypt message bodies, it is prefixed with the epenc namespace
+	EncryptionKey string
 }
 
 type Option func(option *Options)
@@ -24,9 +32,33 @@ func KeyNamespace(ns string) Option {
 	}
 }
 
+// Standard switches the webhook to the Standard Webhooks specification (https://www.standardwebhooks.com)
+// so receivers could verify our messages with off-the-shelf SDKs
+func Standard() Option {
+	return func(option *Options) {
+		option.Standard = true
+	}
+}
+
+// Clock is used to sign and verify timestamps, it is useful to test the timestamp checks deterministically
+func Clock(c clock.Clock) Option {
+	return func(option *Options) {
+		option.Clock = c
+	}
+}
+
+// Encryption encrypts message bodies with the given key (AES-256-GCM) before signing them,
+// and requires incoming messages to be encrypted with the same key. Use GenerateSecret(EncryptionKeyNs) to mint the key
+func Encryption(key string) Option {
+	return func(option *Options) {
+		option.EncryptionKey = key
+	}
+}
+
 type VerifyOptions struct {
 	TimestampToleranceIgnore   bool
 	TimestampToleranceDuration time.Duration
+	ReplayStore                ReplayStore
 }
 
 type VerifyOption func(option *VerifyOptions)
@@ -45,3 +77,10 @@ func TimestampToleranceIgnore() VerifyOption {
 		option.TimestampToleranceIgnore = true
 	}
 }
+
+// ReplayGuard rejects messages whose id was already verified within the timestamp tolerance window
+func ReplayGuard(store ReplayStore) VerifyOption {
+	return func(option *VerifyOptions) {
+		option.ReplayStore = store
+	}
+}
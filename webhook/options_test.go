@@ -5,10 +5,18 @@ import (
 	"time"
 
 	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
 	"github.com/stretchr/testify/require"
 )
 
 func TestOptions(t *testing.T) {
+	whoptions := &Options{}
+	require.False(t, whoptions.Standard)
+	Standard()(whoptions)
+	require.True(t, whoptions.Standard)
+	Clock(testify.Clock(time.Time{}))(whoptions)
+	require.NotNil(t, whoptions.Clock)
+
 	options := &VerifyOptions{}
 	require.False(t, options.TimestampToleranceIgnore)
 	require.Equal(t, time.Duration(0), options.TimestampToleranceDuration)
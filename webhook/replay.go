@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kanthorlabs/common/clock"
+	"github.com/kanthorlabs/common/idempotency"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var (
+	ReplayKeyPrefix          = "webhook/replay/"
+	ReplaySweepInterval      = time.Minute
+	ReplayTimeToLiveFallback = time.Second
+)
+
+// ReplayStore records the id of verified messages
+type ReplayStore interface {
+	// Remember records the id for the given duration.
+	// It returns ErrMessageReplayed if the id has been recorded and not expired yet
+	Remember(ctx context.Context, id string, ttl time.Duration) error
+}
+
+// NewMemoryReplayStore creates a replay store that keeps ids in memory.
+// It is suitable for a single receiver instance only, use the Redis store when you have many of them
+func NewMemoryReplayStore(watch clock.Clock) ReplayStore {
+	return &memoryreplay{clock: watch, ids: make(map[string]time.Time)}
+}
+
+type memoryreplay struct {
+	clock clock.Clock
+	mu    sync.Mutex
+	ids   map[string]time.Time
+	sweep time.Time
+}
+
+func (store *memoryreplay) Remember(ctx context.Context, id string, ttl time.Duration) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.clock.Now()
+	if now.After(store.sweep) {
+		for k, expires := range store.ids {
+			if now.After(expires) {
+				delete(store.ids, k)
+			}
+		}
+		store.sweep = now.Add(ReplaySweepInterval)
+	}
+
+	if expires, exist := store.ids[id]; exist && !now.After(expires) {
+		return ErrMessageReplayed
+	}
+
+	store.ids[id] = now.Add(timetolive(ttl))
+	return nil
+}
+
+// NewRedisReplayStore creates a replay store that keeps ids in Redis so many receivers could share it
+func NewRedisReplayStore(client goredis.UniversalClient) ReplayStore {
+	return &redisreplay{client: client}
+}
+
+type redisreplay struct {
+	client goredis.UniversalClient
+}
+
+func (store *redisreplay) Remember(ctx context.Context, id string, ttl time.Duration) error {
+	ok, err := store.client.SetNX(ctx, ReplayKeyPrefix+id, 1, timetolive(ttl)).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMessageReplayed
+	}
+	return nil
+}
+
+// NewIdempotencyReplayStore creates a replay store on top of an idempotency instance.
+// The ttl is ignored because ids expire after the time to live of the idempotency configuration,
+// make sure it is longer than the timestamp tolerance window
+func NewIdempotencyReplayStore(idemp idempotency.Idempotency) ReplayStore {
+	return &idempotencyreplay{idemp: idemp}
+}
+
+type idempotencyreplay struct {
+	idemp idempotency.Idempotency
+}
+
+func (store *idempotencyreplay) Remember(ctx context.Context, id string, ttl time.Duration) error {
+	err := store.idemp.Validate(ctx, ReplayKeyPrefix+id)
+	if errors.Is(err, idempotency.ErrConflict) {
+		return ErrMessageReplayed
+	}
+	return err
+}
+
+// timetolive makes sure we always remember the id for a short while even if the window is already closed
+func timetolive(ttl time.Duration) time.Duration {
+	if ttl < ReplayTimeToLiveFallback {
+		return ReplayTimeToLiveFallback
+	}
+	return ttl
+}
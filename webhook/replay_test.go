@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanthorlabs/common/clock"
+	"github.com/kanthorlabs/common/containers"
+	"github.com/kanthorlabs/common/idempotency"
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify_ReplayGuard(t *testing.T) {
+	wh, err := New(keys)
+	require.NoError(t, err)
+
+	body := testdata.Fake.Lorem().Sentence(10)
+
+	t.Run("OK", func(st *testing.T) {
+		store := NewMemoryReplayStore(clock.New())
+
+		req, _, _ := signed(st, wh, body)
+		require.NoError(st, wh.Verify(req, ReplayGuard(store)))
+	})
+
+	t.Run("OK - ignore timestamp check", func(st *testing.T) {
+		store := NewMemoryReplayStore(clock.New())
+
+		req, _, _ := signed(st, wh, body)
+		require.NoError(st, wh.Verify(req, TimestampToleranceIgnore(), ReplayGuard(store)))
+		require.ErrorIs(st, wh.Verify(req, TimestampToleranceIgnore(), ReplayGuard(store)), ErrMessageReplayed)
+	})
+
+	t.Run("KO - replayed error", func(st *testing.T) {
+		store := NewMemoryReplayStore(clock.New())
+
+		req, _, _ := signed(st, wh, body)
+		require.NoError(st, wh.Verify(req, ReplayGuard(store)))
+		require.ErrorIs(st, wh.Verify(req, ReplayGuard(store)), ErrMessageReplayed)
+	})
+
+	t.Run("KO - store unavailable error", func(st *testing.T) {
+		req, _, _ := signed(st, wh, body)
+		err := wh.Verify(req, ReplayGuard(&brokenreplay{}))
+		require.ErrorIs(st, err, ErrReplayStoreUnavailable)
+		require.ErrorIs(st, err, testdata.ErrGeneric)
+
+		// the sender must retry the message later instead of dropping it as a malformed one
+		w := httptest.NewRecorder()
+		req, _, _ = signed(st, wh, body)
+		Middleware(wh, Verification(ReplayGuard(&brokenreplay{})))(http.NotFoundHandler()).ServeHTTP(w, req)
+		require.Equal(st, http.StatusServiceUnavailable, w.Code)
+		require.Contains(st, w.Body.String(), ErrReplayStoreUnavailable.Error())
+		require.NotContains(st, w.Body.String(), testdata.ErrGeneric.Error())
+	})
+
+	t.Run("KO - id empty error", func(st *testing.T) {
+		store := NewMemoryReplayStore(clock.New())
+
+		req, _, _ := signed(st, wh, body)
+		req.Header.Del(HeaderId)
+		req.Header.Set(HeaderSignature, wh.Sign("", req.Header.Get(HeaderTimestamp), body)[0])
+		require.ErrorIs(st, wh.Verify(req, ReplayGuard(store)), ErrMessageIdEmpty)
+	})
+
+	t.Run("KO - signature mismatch does not record the id", func(st *testing.T) {
+		store := NewMemoryReplayStore(clock.New())
+
+		req, id, _ := signed(st, wh, body)
+		req.Header.Set(HeaderSignature, "v1,"+uuid.NewString())
+		require.ErrorIs(st, wh.Verify(req, ReplayGuard(store)), ErrSignatureMismatch)
+
+		require.NoError(st, store.Remember(context.Background(), id, time.Minute))
+	})
+}
+
+func TestMemoryReplayStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryReplayStore(testify.Clock(now))
+	instance := store.(*memoryreplay)
+	ctx := context.Background()
+
+	t.Run("OK - expired id could be remembered again", func(st *testing.T) {
+		id := uuid.NewString()
+		require.NoError(st, store.Remember(ctx, id, time.Millisecond))
+		require.ErrorIs(st, store.Remember(ctx, id, time.Minute), ErrMessageReplayed)
+
+		instance.clock = testify.Clock(now.Add(ReplayTimeToLiveFallback + time.Millisecond))
+		defer func() { instance.clock = testify.Clock(now) }()
+		require.NoError(st, store.Remember(ctx, id, time.Minute))
+		require.ErrorIs(st, store.Remember(ctx, id, time.Minute), ErrMessageReplayed)
+	})
+
+	t.Run("OK - sweep expired ids", func(st *testing.T) {
+		instance.ids[uuid.NewString()] = now.Add(-time.Hour)
+		instance.sweep = time.Time{}
+
+		require.NoError(st, store.Remember(ctx, uuid.NewString(), time.Minute))
+		for _, expires := range instance.ids {
+			require.True(st, expires.After(now))
+		}
+	})
+}
+
+func TestIdempotencyReplayStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("OK", func(st *testing.T) {
+		store := NewIdempotencyReplayStore(&idemp{keys: map[string]bool{}})
+
+		id := uuid.NewString()
+		require.NoError(st, store.Remember(ctx, id, time.Minute))
+		require.ErrorIs(st, store.Remember(ctx, id, time.Minute), ErrMessageReplayed)
+	})
+
+	t.Run("KO - idempotency error", func(st *testing.T) {
+		store := NewIdempotencyReplayStore(&idemp{err: testdata.ErrGeneric})
+		require.ErrorIs(st, store.Remember(ctx, uuid.NewString(), time.Minute), testdata.ErrGeneric)
+	})
+}
+
+func TestRedisReplayStore(t *testing.T) {
+	ctx := context.Background()
+	container, err := containers.Redis(ctx, "kanthorlabs-common-webhook")
+	require.NoError(t, err)
+
+	uri, err := containers.RedisConnectionString(ctx, container)
+	require.NoError(t, err)
+	conf, err := goredis.ParseURL(uri)
+	require.NoError(t, err)
+
+	client := goredis.NewClient(conf)
+	defer client.Close()
+
+	store := NewRedisReplayStore(client)
+
+	t.Run("OK", func(st *testing.T) {
+		id := uuid.NewString()
+		require.NoError(st, store.Remember(ctx, id, time.Minute))
+		require.ErrorIs(st, store.Remember(ctx, id, time.Minute), ErrMessageReplayed)
+	})
+
+	t.Run("KO - redis error", func(st *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		require.ErrorIs(st, store.Remember(cctx, uuid.NewString(), time.Minute), context.Canceled)
+	})
+}
+
+type idemp struct {
+	keys map[string]bool
+	err  error
+}
+
+func (i *idemp) Connect(ctx context.Context) error    { return nil }
+func (i *idemp) Readiness() error                     { return nil }
+func (i *idemp) Liveness() error                      { return nil }
+func (i *idemp) Disconnect(ctx context.Context) error { return nil }
+
+func (i *idemp) Validate(ctx context.Context, key string) error {
+	if i.err != nil {
+		return i.err
+	}
+	if i.keys[key] {
+		return idempotency.ErrConflict
+	}
+	i.keys[key] = true
+	return nil
+}
+
+// brokenreplay is a store whose backend is down
+type brokenreplay struct{}
+
+func (store *brokenreplay) Remember(ctx context.Context, id string, ttl time.Duration) error {
+	return testdata.ErrGeneric
+}
@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+)
+
+var (
+	SecretEntropy            = 32
+	SecretRetainDefault      = 1
+	SecretGracePeriodDefault = time.Hour * 24
+)
+
+// GenerateSecret mints a key in the given namespace from crypto-random entropy.
+// The entropy is base64 encoded so the key works with both our format and the Standard Webhooks one
+func GenerateSecret(ns string) (string, error) {
+	entropy := make([]byte, SecretEntropy)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return idx.Build(ns, base64.StdEncoding.EncodeToString(entropy)), nil
+}
+
+// Secret is a key and its lifetime in milliseconds, ExpiresAt is zero when the key never expires
+type Secret struct {
+	Value     string `json:"value" yaml:"value" mapstructure:"value"`
+	CreatedAt int64  `json:"created_at" yaml:"created_at" mapstructure:"created_at"`
+	ExpiresAt int64  `json:"expires_at" yaml:"expires_at" mapstructure:"expires_at"`
+}
+
+func (secret *Secret) Expired(now time.Time) bool {
+	return secret.ExpiresAt > 0 && now.UnixMilli() >= secret.ExpiresAt
+}
+
+// String prints the fingerprint of the key instead of the key itself so secrets never end up in logs
+func (secret Secret) String() string {
+	return fmt.Sprintf("%s (created_at:%d expires_at:%d)", fingerprint(secret.Value), secret.CreatedAt, secret.ExpiresAt)
+}
+
+// fingerprint identifies the key by its namespace and the first bytes of its SHA-256
+func fingerprint(key string) string {
+	ns, _, _ := strings.Cut(key, "_")
+	sum := sha256.Sum256([]byte(key))
+	return ns + "_" + hex.EncodeToString(sum[:4])
+}
+
+// SecretSet is the keys of an endpoint, the newest one is the first one
+type SecretSet struct {
+	Secrets []Secret `json:"secrets" yaml:"secrets" mapstructure:"secrets"`
+}
+
+// NewSecretSet creates a set with a newly generated key
+func NewSecretSet(ns string, now time.Time) (*SecretSet, error) {
+	set := &SecretSet{}
+	if _, err := set.Rotate(ns, now); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+type RotateOptions struct {
+	Retain      int
+	GracePeriod time.Duration
+}
+
+type RotateOption func(option *RotateOptions)
+
+// Retain is the number of previous keys that are still valid after the rotation
+func Retain(count int) RotateOption {
+	return func(option *RotateOptions) {
+		if count < 0 || count >= MaxKeys {
+			panic(ErrSecretRetainOutOfRange)
+		}
+		option.Retain = count
+	}
+}
+
+// GracePeriod is how long previous keys are still valid after the rotation
+func GracePeriod(duration time.Duration) RotateOption {
+	return func(option *RotateOptions) {
+		option.GracePeriod = duration
+	}
+}
+
+// Rotate prepends a newly generated key and returns it.
+// Previous keys that are retained expire after the grace period, other ones are removed immediately
+func (set *SecretSet) Rotate(ns string, now time.Time, withOptions ...RotateOption) (string, error) {
+	options := &RotateOptions{Retain: SecretRetainDefault, GracePeriod: SecretGracePeriodDefault}
+	for i := range withOptions {
+		withOptions[i](options)
+	}
+
+	value, err := GenerateSecret(ns)
+	if err != nil {
+		return "", err
+	}
+
+	set.Prune(now)
+
+	deadline := now.Add(options.GracePeriod).UnixMilli()
+	previous := set.Secrets
+	if len(previous) > options.Retain {
+		previous = previous[:options.Retain]
+	}
+	for i := range previous {
+		// never extend the lifetime of a key that is going to expire sooner
+		if previous[i].ExpiresAt == 0 || previous[i].ExpiresAt > deadline {
+			previous[i].ExpiresAt = deadline
+		}
+	}
+
+	set.Secrets = append([]Secret{{Value: value, CreatedAt: now.UnixMilli()}}, previous...)
+	return value, nil
+}
+
+// Prune removes expired keys
+func (set *SecretSet) Prune(now time.Time) {
+	var secrets []Secret
+	for _, secret := range set.Secrets {
+		if !secret.Expired(now) {
+			secrets = append(secrets, secret)
+		}
+	}
+	set.Secrets = secrets
+}
+
+// Keys returns the keys that are valid at the given time, it is ready to be used with New
+func (set *SecretSet) Keys(now time.Time) []string {
+	var keys []string
+	for _, secret := range set.Secrets {
+		if !secret.Expired(now) {
+			keys = append(keys, secret.Value)
+		}
+	}
+	return keys
+}
+
+func (set *SecretSet) String() string {
+	return fmt.Sprintf("%v", set.Secrets)
+}
+
+// Value implements the driver Valuer interface.
+func (set *SecretSet) Value() (driver.Value, error) {
+	if set == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(set)
+	return string(data), err
+}
+
+// Scan implements the Scanner interface.
+func (set *SecretSet) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("%w: %T", ErrSecretSetScan, value)
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, set)
+}
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/testdata"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		key, err := GenerateSecret(DefaultKeyNs)
+		require.NoError(st, err)
+		require.True(st, strings.HasPrefix(key, DefaultKeyNs+"_"))
+
+		another, err := GenerateSecret(DefaultKeyNs)
+		require.NoError(st, err)
+		require.NotEqual(st, key, another)
+
+		_, err = New([]string{key, another})
+		require.NoError(st, err)
+	})
+
+	t.Run("OK - standard", func(st *testing.T) {
+		key, err := GenerateSecret(StandardKeyNs)
+		require.NoError(st, err)
+
+		_, err = New([]string{key}, Standard())
+		require.NoError(st, err)
+	})
+}
+
+func TestSecretSet_Rotate(t *testing.T) {
+	now := time.Now()
+
+	t.Run("OK", func(st *testing.T) {
+		set, err := NewSecretSet(DefaultKeyNs, now)
+		require.NoError(st, err)
+		first := set.Secrets[0].Value
+		require.Zero(st, set.Secrets[0].ExpiresAt)
+
+		second, err := set.Rotate(DefaultKeyNs, now, GracePeriod(time.Hour))
+		require.NoError(st, err)
+		require.Equal(st, []string{second, first}, set.Keys(now))
+		require.Equal(st, now.Add(time.Hour).UnixMilli(), set.Secrets[1].ExpiresAt)
+
+		// the previous key is expired after the grace period
+		require.Equal(st, []string{second}, set.Keys(now.Add(time.Hour)))
+	})
+
+	t.Run("OK - retain", func(st *testing.T) {
+		set, err := NewSecretSet(DefaultKeyNs, now)
+		require.NoError(st, err)
+
+		for i := 0; i < 5; i++ {
+			_, err := set.Rotate(DefaultKeyNs, now, Retain(2))
+			require.NoError(st, err)
+		}
+		require.Len(st, set.Keys(now), 3)
+
+		_, err = set.Rotate(DefaultKeyNs, now, Retain(0))
+		require.NoError(st, err)
+		require.Len(st, set.Keys(now), 1)
+	})
+
+	t.Run("OK - never extend the deadline", func(st *testing.T) {
+		set, err := NewSecretSet(DefaultKeyNs, now)
+		require.NoError(st, err)
+
+		_, err = set.Rotate(DefaultKeyNs, now, GracePeriod(time.Minute), Retain(2))
+		require.NoError(st, err)
+		_, err = set.Rotate(DefaultKeyNs, now, GracePeriod(time.Hour), Retain(2))
+		require.NoError(st, err)
+
+		require.Equal(st, now.Add(time.Hour).UnixMilli(), set.Secrets[1].ExpiresAt)
+		require.Equal(st, now.Add(time.Minute).UnixMilli(), set.Secrets[2].ExpiresAt)
+	})
+
+	t.Run("OK - prune", func(st *testing.T) {
+		set, err := NewSecretSet(DefaultKeyNs, now)
+		require.NoError(st, err)
+		_, err = set.Rotate(DefaultKeyNs, now, GracePeriod(time.Minute))
+		require.NoError(st, err)
+
+		set.Prune(now.Add(time.Minute))
+		require.Len(st, set.Secrets, 1)
+	})
+
+	t.Run("KO - retain out of range", func(st *testing.T) {
+		require.PanicsWithValue(st, ErrSecretRetainOutOfRange, func() {
+			Retain(MaxKeys)(&RotateOptions{})
+		})
+	})
+}
+
+func TestSecretSet_Serialize(t *testing.T) {
+	set, err := NewSecretSet(StandardKeyNs, time.Now())
+	require.NoError(t, err)
+	_, err = set.Rotate(StandardKeyNs, time.Now())
+	require.NoError(t, err)
+
+	t.Run("OK - json", func(st *testing.T) {
+		data, err := json.Marshal(set)
+		require.NoError(st, err)
+
+		var decoded SecretSet
+		require.NoError(st, json.Unmarshal(data, &decoded))
+		require.Equal(st, set, &decoded)
+	})
+
+	t.Run("OK - string never prints secrets", func(st *testing.T) {
+		outputs := []string{set.String(), fmt.Sprintf("%v", set), fmt.Sprintf("%+v", *set), fmt.Sprintf("%s", set.Secrets[0])}
+		for _, output := range outputs {
+			require.Contains(st, output, StandardKeyNs+"_")
+			for _, secret := range set.Secrets {
+				_, entropy, _ := strings.Cut(secret.Value, "_")
+				require.NotContains(st, output, entropy)
+			}
+		}
+	})
+
+	t.Run("OK - yaml", func(st *testing.T) {
+		data, err := yaml.Marshal(set)
+		require.NoError(st, err)
+
+		var decoded SecretSet
+		require.NoError(st, yaml.Unmarshal(data, &decoded))
+		require.Equal(st, set, &decoded)
+	})
+
+	t.Run("OK - database", func(st *testing.T) {
+		value, err := set.Value()
+		require.NoError(st, err)
+
+		var decoded SecretSet
+		require.NoError(st, decoded.Scan(value))
+		require.Equal(st, set, &decoded)
+
+		var bytes SecretSet
+		require.NoError(st, bytes.Scan([]byte(value.(string))))
+		require.Equal(st, set, &bytes)
+	})
+
+	t.Run("KO - scan error", func(st *testing.T) {
+		var decoded SecretSet
+		require.ErrorIs(st, decoded.Scan(testdata.Fake.Int64()), ErrSecretSetScan)
+	})
+}
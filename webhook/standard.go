@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	StandardKeyNs              = "whsec"
+	StandardHeaderId           = "webhook-id"
+	StandardHeaderTimestamp    = "webhook-timestamp"
+	StandardHeaderSignature    = "webhook-signature"
+	StandardSignatureVersion   = "v1"
+	StandardSignatureDivider   = " "
+	StandardVersionSignDivider = ","
+)
+
+// standard implements the Standard Webhooks specification:
+//   - secrets are base64 encoded and prefixed with whsec_
+//   - signatures are base64 encoded HMAC-SHA256 with the v1 version
+//   - timestamps are in seconds
+type standard struct {
+	secrets [][]byte
+}
+
+func newStandard(keys []string, ns string) (*standard, error) {
+	scheme := &standard{}
+	for i := range keys {
+		secret, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(keys[i], ns+"_"))
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("keys[%d] must be a base64 encoded secret that is prefixed with %s_", i, ns)
+		}
+		scheme.secrets = append(scheme.secrets, secret)
+	}
+	return scheme, nil
+}
+
+func (scheme *standard) headers() (string, string, string) {
+	return StandardHeaderId, StandardHeaderTimestamp, StandardHeaderSignature
+}
+
+func (scheme *standard) sign(i int, data string) string {
+	return StandardSignatureVersion + StandardVersionSignDivider + base64.StdEncoding.EncodeToString(scheme.mac(scheme.secrets[i], data))
+}
+
+func (scheme *standard) verify(data, signatures string) error {
+	for _, versionsign := range strings.Split(signatures, StandardSignatureDivider) {
+		version, sign, found := strings.Cut(versionsign, StandardVersionSignDivider)
+		if !found || version != StandardSignatureVersion {
+			continue
+		}
+
+		compare, err := base64.StdEncoding.DecodeString(sign)
+		if err != nil {
+			continue
+		}
+
+		for i := range scheme.secrets {
+			if hmac.Equal(scheme.mac(scheme.secrets[i], data), compare) {
+				return nil
+			}
+		}
+	}
+
+	return ErrSignatureMismatch
+}
+
+func (scheme *standard) timestamp(ts string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrMessageTimestampMalformed
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+func (scheme *standard) format(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func (scheme *standard) divider() string {
+	return StandardSignatureDivider
+}
+
+func (scheme *standard) mac(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
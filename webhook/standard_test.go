@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kanthorlabs/common/idx"
+	"github.com/kanthorlabs/common/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// the example of the Standard Webhooks specification
+var (
+	standardSecret    = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	standardId        = "msg_p5jXN8AQM9LWM0D4loKWxJek"
+	standardTimestamp = "1614265330"
+	standardBody      = `{"test": 2432232314}`
+	standardSignature = "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="
+)
+
+func TestStandard_New(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		wh, err := New([]string{standardSecret}, Standard())
+		require.NoError(st, err)
+		require.NotNil(st, wh)
+	})
+
+	t.Run("KO - key namespace error", func(st *testing.T) {
+		_, err := New(keys, Standard())
+		require.ErrorContains(st, err, " must be started with whsec")
+	})
+
+	t.Run("KO - key base64 error", func(st *testing.T) {
+		_, err := New([]string{idx.Build(StandardKeyNs, "!!!")}, Standard())
+		require.ErrorContains(st, err, "must be a base64 encoded secret")
+	})
+}
+
+func TestStandard_Sign(t *testing.T) {
+	wh, err := New([]string{standardSecret}, Standard())
+	require.NoError(t, err)
+
+	signatures := wh.Sign(standardId, standardTimestamp, standardBody)
+	require.Equal(t, []string{standardSignature}, signatures)
+}
+
+func TestStandard_Verify(t *testing.T) {
+	secrets := []string{
+		idx.Build(StandardKeyNs, base64.StdEncoding.EncodeToString([]byte(utils.RandomString(32)))),
+		standardSecret,
+	}
+	wh, err := New(secrets, Standard())
+	require.NoError(t, err)
+
+	t.Run("OK - specification example", func(st *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", io.NopCloser(strings.NewReader(standardBody)))
+		req.Header.Set(StandardHeaderId, standardId)
+		req.Header.Set(StandardHeaderTimestamp, standardTimestamp)
+		req.Header.Set(StandardHeaderSignature, "v1,invalid v2,"+strings.TrimPrefix(standardSignature, "v1,")+" "+standardSignature)
+
+		require.NoError(st, wh.Verify(req, TimestampToleranceIgnore()))
+	})
+
+	t.Run("OK - timestamp in seconds", func(st *testing.T) {
+		id := idx.New("msg")
+		ts := fmt.Sprintf("%d", time.Now().Unix())
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", io.NopCloser(strings.NewReader(standardBody)))
+		req.Header.Set(StandardHeaderId, id)
+		req.Header.Set(StandardHeaderTimestamp, ts)
+		req.Header.Set(StandardHeaderSignature, strings.Join(wh.Sign(id, ts, standardBody), StandardSignatureDivider))
+
+		require.NoError(st, wh.Verify(req))
+	})
+
+	t.Run("KO - timestamp in milliseconds error", func(st *testing.T) {
+		id := idx.New("msg")
+		ts := fmt.Sprintf("%d", time.Now().UnixMilli())
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", io.NopCloser(strings.NewReader(standardBody)))
+		req.Header.Set(StandardHeaderId, id)
+		req.Header.Set(StandardHeaderTimestamp, ts)
+		req.Header.Set(StandardHeaderSignature, strings.Join(wh.Sign(id, ts, standardBody), StandardSignatureDivider))
+
+		require.ErrorIs(st, wh.Verify(req), ErrMessageTimestampTooNew)
+	})
+
+	t.Run("KO - timestamp malformed error", func(st *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", io.NopCloser(strings.NewReader(standardBody)))
+		req.Header.Set(StandardHeaderId, standardId)
+		req.Header.Set(StandardHeaderTimestamp, "xxx")
+		req.Header.Set(StandardHeaderSignature, standardSignature)
+
+		require.ErrorIs(st, wh.Verify(req), ErrMessageTimestampMalformed)
+	})
+
+	t.Run("KO - signature mismatch error", func(st *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", io.NopCloser(strings.NewReader(standardBody+" ")))
+		req.Header.Set(StandardHeaderId, standardId)
+		req.Header.Set(StandardHeaderTimestamp, standardTimestamp)
+		req.Header.Set(StandardHeaderSignature, standardSignature+" v1,!!!")
+
+		require.ErrorIs(st, wh.Verify(req, TimestampToleranceIgnore()), ErrSignatureMismatch)
+	})
+}
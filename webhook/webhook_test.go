@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/kanthorlabs/common/cipher/signature"
 	"github.com/kanthorlabs/common/idx"
 	"github.com/kanthorlabs/common/testdata"
+	"github.com/kanthorlabs/common/testify"
 	"github.com/kanthorlabs/common/utils"
 	"github.com/stretchr/testify/require"
 )
@@ -72,6 +74,34 @@ func TestSign(t *testing.T) {
 	})
 }
 
+func TestHeaders(t *testing.T) {
+	t.Run("OK", func(st *testing.T) {
+		wh, err := New(keys)
+		require.NoError(st, err)
+
+		id := idx.New("msg")
+		body := testdata.Fake.Lorem().Sentence(10)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(body))
+		req.Header = wh.Headers(id, body)
+		require.Equal(st, id, req.Header.Get(HeaderId))
+		require.NoError(st, wh.Verify(req))
+	})
+
+	t.Run("OK - standard", func(st *testing.T) {
+		wh, err := New([]string{standardSecret}, Standard())
+		require.NoError(st, err)
+
+		id := idx.New("msg")
+		body := testdata.Fake.Lorem().Sentence(10)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(body))
+		req.Header = wh.Headers(id, body)
+		require.Len(st, req.Header.Get(StandardHeaderTimestamp), 10)
+		require.NoError(st, wh.Verify(req))
+	})
+}
+
 func TestVerify(t *testing.T) {
 	wh, err := New(keys)
 	require.NoError(t, err)
@@ -95,6 +125,10 @@ func TestVerify(t *testing.T) {
 		req.Header.Set(HeaderSignature, signature)
 
 		require.NoError(st, wh.Verify(req, TimestampToleranceDuration(time.Hour)))
+
+		restored, err := io.ReadAll(req.Body)
+		require.NoError(st, err)
+		require.Equal(st, body, string(restored))
 	})
 
 	t.Run("OK - ignore timestamp check", func(st *testing.T) {
@@ -203,6 +237,76 @@ func TestVerify(t *testing.T) {
 	})
 }
 
+func TestSignRequest(t *testing.T) {
+	now := time.Now().UTC()
+	wh, err := New(keys, Clock(testify.Clock(now)))
+	require.NoError(t, err)
+
+	t.Run("OK", func(st *testing.T) {
+		body := testdata.Fake.Lorem().Sentence(10)
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(body))
+
+		id := idx.New("msg")
+		require.NoError(st, wh.SignRequest(req, id, now))
+		require.Equal(st, fmt.Sprintf("%d", now.UnixMilli()), req.Header.Get(HeaderTimestamp))
+		require.NoError(st, wh.Verify(req))
+
+		// the body is still readable
+		data, err := io.ReadAll(req.Body)
+		require.NoError(st, err)
+		require.Equal(st, body, string(data))
+	})
+
+	t.Run("OK - empty body", func(st *testing.T) {
+		req := &http.Request{Method: http.MethodGet}
+		require.NoError(st, wh.SignRequest(req, idx.New("msg"), now))
+		require.NotEmpty(st, req.Header.Get(HeaderSignature))
+	})
+}
+
+func TestVerify_Clock(t *testing.T) {
+	// timestamps are in milliseconds
+	now := time.UnixMilli(time.Now().UnixMilli()).UTC()
+	wh, err := New(keys, Clock(testify.Clock(now)))
+	require.NoError(t, err)
+
+	signed := func(ts time.Time) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/demo", strings.NewReader(testdata.Fake.Lorem().Sentence(10)))
+		require.NoError(t, wh.SignRequest(req, idx.New("msg"), ts))
+		return req
+	}
+
+	t.Run("OK - at the edges of the tolerance window", func(st *testing.T) {
+		require.NoError(st, wh.Verify(signed(now.Add(-ToleranceDurationDefault))))
+		require.NoError(st, wh.Verify(signed(now.Add(ToleranceDurationDefault))))
+	})
+
+	t.Run("KO - too old error", func(st *testing.T) {
+		req := signed(now.Add(-ToleranceDurationDefault - time.Millisecond))
+		require.ErrorIs(st, wh.Verify(req), ErrMessageTimestampTooOld)
+	})
+
+	t.Run("KO - too new error", func(st *testing.T) {
+		req := signed(now.Add(ToleranceDurationDefault + time.Millisecond))
+		require.ErrorIs(st, wh.Verify(req), ErrMessageTimestampTooNew)
+	})
+
+	t.Run("OK - replay ttl follows the clock", func(st *testing.T) {
+		store := &ttlreplay{}
+		require.NoError(st, wh.Verify(signed(now.Add(-time.Minute)), ReplayGuard(store)))
+		require.Equal(st, ToleranceDurationDefault-time.Minute, store.ttl)
+	})
+}
+
+type ttlreplay struct {
+	ttl time.Duration
+}
+
+func (store *ttlreplay) Remember(ctx context.Context, id string, ttl time.Duration) error {
+	store.ttl = ttl
+	return nil
+}
+
 var (
 	keys = []string{
 		idx.Build(DefaultKeyNs, utils.RandomString(128)),
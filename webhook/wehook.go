@@ -1,23 +1,36 @@
 package webhook
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kanthorlabs/common/cipher/signature"
+	"github.com/kanthorlabs/common/clock"
 	"github.com/kanthorlabs/common/validator"
 )
 
+// New creates a webhook instance that signs and verifies messages with the given keys.
+// By default, it uses our own format, use the Standard option to follow the Standard Webhooks specification
 func New(keys []string, withOptions ...Option) (Webhook, error) {
-	options := &Options{
-		KeyNamespace: DefaultKeyNs,
-	}
+	options := &Options{}
 	for i := range withOptions {
 		withOptions[i](options)
 	}
+	if options.KeyNamespace == "" {
+		options.KeyNamespace = DefaultKeyNs
+		if options.Standard {
+			options.KeyNamespace = StandardKeyNs
+		}
+	}
+	if options.Clock == nil {
+		options.Clock = clock.New()
+	}
 
 	err := validator.Validate(
 		validator.SliceRequired("keys", keys),
@@ -30,26 +43,130 @@ func New(keys []string, withOptions ...Option) (Webhook, error) {
 		return nil, err
 	}
 
-	return &webhook{keys: keys}, nil
+	var s scheme = &kanthor{keys: keys}
+	if options.Standard {
+		if s, err = newStandard(keys, options.KeyNamespace); err != nil {
+			return nil, err
+		}
+	}
+
+	wh := &webhook{keys: keys, scheme: s, clock: options.Clock}
+	if options.EncryptionKey != "" {
+		if wh.encryption, err = newEncryption(options.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return wh, nil
 }
 
 type Webhook interface {
 	Sign(id, ts, body string) []string
+	// Verify verifies the signature and the timestamp of the request.
+	// The body is restored after verification so the next handlers could read it again
 	Verify(req *http.Request, withOptions ...VerifyOption) error
+	// Message returns the id and the timestamp of the message from the request headers
+	Message(req *http.Request) (*Message, error)
+	// Headers returns the id, timestamp and signature headers of the message that is sent at the current time
+	Headers(id, body string) http.Header
+	// Seal returns the headers and the body of the message that is sent at the current time.
+	// The body is encrypted before signing when the Encryption option is used, otherwise it is returned as it is
+	Seal(id, body string) (http.Header, string, error)
+	// SignRequest sets the id, timestamp and signature headers of the request for the given id and timestamp.
+	// The body is restored after signing, or replaced with the encrypted one when the Encryption option is used
+	SignRequest(req *http.Request, id string, ts time.Time) error
+}
+
+// Message is the identity of a webhook message
+type Message struct {
+	Id        string
+	Timestamp time.Time
+}
+
+// scheme is the format of the signatures and timestamps we use to sign and verify messages
+type scheme interface {
+	headers() (string, string, string)
+	sign(i int, data string) string
+	verify(data, signatures string) error
+	timestamp(ts string) (time.Time, error)
+	format(t time.Time) string
+	divider() string
 }
 
 type webhook struct {
-	keys []string
+	keys       []string
+	scheme     scheme
+	clock      clock.Clock
+	encryption *encryption
 }
 
 func (wh *webhook) Sign(id, ts, body string) []string {
 	var signatures []string
 	for i := range wh.keys {
-		signatures = append(signatures, signature.Sign(wh.keys[i], fmt.Sprintf("%s.%s.%s", id, ts, body)))
+		signatures = append(signatures, wh.scheme.sign(i, fmt.Sprintf("%s.%s.%s", id, ts, body)))
 	}
 	return signatures
 }
 
+func (wh *webhook) Headers(id, body string) http.Header {
+	headers := make(http.Header)
+	wh.headers(headers, id, wh.clock.Now(), body)
+	return headers
+}
+
+func (wh *webhook) Seal(id, body string) (http.Header, string, error) {
+	headers := make(http.Header)
+	body, err := wh.encrypt(headers, id, body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wh.headers(headers, id, wh.clock.Now(), body)
+	return headers, body, nil
+}
+
+func (wh *webhook) SignRequest(req *http.Request, id string, ts time.Time) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+
+	sealed, err := wh.encrypt(req.Header, id, string(body))
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(strings.NewReader(sealed))
+	req.ContentLength = int64(len(sealed))
+
+	wh.headers(req.Header, id, ts, sealed)
+	return nil
+}
+
+func (wh *webhook) encrypt(headers http.Header, id, body string) (string, error) {
+	if wh.encryption == nil {
+		return body, nil
+	}
+
+	headers.Set(HeaderEncryption, EncryptionAlgorithm)
+	headers.Set("Content-Type", ContentTypeEncrypted)
+	return wh.encryption.encrypt(id, body)
+}
+
+func (wh *webhook) headers(headers http.Header, id string, t time.Time, body string) {
+	headerId, headerTimestamp, headerSignature := wh.scheme.headers()
+	ts := wh.scheme.format(t)
+
+	headers.Set(headerId, id)
+	headers.Set(headerTimestamp, ts)
+	headers.Set(headerSignature, strings.Join(wh.Sign(id, ts, SignedBody(headers.Get(HeaderEncryption), body)), wh.scheme.divider()))
+}
+
 func (wh *webhook) Verify(req *http.Request, withOptions ...VerifyOption) error {
 	options := &VerifyOptions{
 		TimestampToleranceDuration: ToleranceDurationDefault,
@@ -60,39 +177,103 @@ func (wh *webhook) Verify(req *http.Request, withOptions ...VerifyOption) error
 
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrMessageBodyRead, err)
 	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	headerId, headerTimestamp, headerSignature := wh.scheme.headers()
 
-	timestamp := req.Header.Get(HeaderTimestamp)
+	timestamp := req.Header.Get(headerTimestamp)
 	if err := wh.verifyTimestamp(timestamp, options); err != nil {
 		return err
 	}
 
-	id := req.Header.Get(HeaderId)
-	expected := req.Header.Get(HeaderSignature)
+	id := req.Header.Get(headerId)
+	expected := req.Header.Get(headerSignature)
 
-	data := fmt.Sprintf("%s.%s.%s", id, timestamp, body)
-	if err := signature.VerifyAny(wh.keys, data, expected); err != nil {
+	data := fmt.Sprintf("%s.%s.%s", id, timestamp, SignedBody(req.Header.Get(HeaderEncryption), string(body)))
+	if err := wh.scheme.verify(data, expected); err != nil {
 		return ErrSignatureMismatch
 	}
 
+	// decrypt the body only after the signature is validated so we never touch unauthentic ciphertext
+	if err := wh.decrypt(req, id, body); err != nil {
+		return err
+	}
+
+	// only record the id of authentic messages, otherwise anyone could poison the store
+	if options.ReplayStore != nil {
+		return wh.verifyReplay(req, id, timestamp, options)
+	}
+
 	return nil
 }
 
+func (wh *webhook) decrypt(req *http.Request, id string, body []byte) error {
+	algorithm := req.Header.Get(HeaderEncryption)
+	if algorithm == "" {
+		if wh.encryption != nil {
+			return ErrMessageEncryptionRequired
+		}
+		return nil
+	}
+	if algorithm != EncryptionAlgorithm || wh.encryption == nil {
+		return ErrMessageEncryptionUnsupported
+	}
+
+	plaintext, err := wh.encryption.decrypt(id, body)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(plaintext))
+	req.ContentLength = int64(len(plaintext))
+	return nil
+}
+
+func (wh *webhook) verifyReplay(req *http.Request, id, ts string, options *VerifyOptions) error {
+	if id == "" {
+		return ErrMessageIdEmpty
+	}
+
+	// remember the id as long as the message could pass the timestamp check
+	ttl := options.TimestampToleranceDuration
+	if !options.TimestampToleranceIgnore {
+		t, _ := wh.scheme.timestamp(ts)
+		ttl = t.Add(options.TimestampToleranceDuration).Sub(wh.clock.Now())
+	}
+
+	err := options.ReplayStore.Remember(req.Context(), id, ttl)
+	// the message is fine when the store is down, tell the sender to retry it later instead of dropping it
+	if err != nil && !errors.Is(err, ErrMessageReplayed) {
+		return fmt.Errorf("%w: %w", ErrReplayStoreUnavailable, err)
+	}
+	return err
+}
+
+func (wh *webhook) Message(req *http.Request) (*Message, error) {
+	headerId, headerTimestamp, _ := wh.scheme.headers()
+
+	timestamp, err := wh.scheme.timestamp(req.Header.Get(headerTimestamp))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{Id: req.Header.Get(headerId), Timestamp: timestamp}, nil
+}
+
 func (wh *webhook) verifyTimestamp(ts string, options *VerifyOptions) error {
-	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	t, err := wh.scheme.timestamp(ts)
 	if err != nil {
-		return ErrMessageTimestampMalformed
+		return err
 	}
 
 	if options.TimestampToleranceIgnore {
 		return nil
 	}
 
-	t := time.UnixMilli(timestamp)
 	low := t.Add(-options.TimestampToleranceDuration).UnixMilli()
 	high := t.Add(options.TimestampToleranceDuration).UnixMilli()
-	now := time.Now().UnixMilli()
+	now := wh.clock.Now().UnixMilli()
 
 	if now > high {
 		return ErrMessageTimestampTooOld
@@ -103,3 +284,36 @@ func (wh *webhook) verifyTimestamp(ts string, options *VerifyOptions) error {
 
 	return nil
 }
+
+// kanthor is our own format: hex encoded signatures of the signature package and timestamps in milliseconds
+type kanthor struct {
+	keys []string
+}
+
+func (scheme *kanthor) headers() (string, string, string) {
+	return HeaderId, HeaderTimestamp, HeaderSignature
+}
+
+func (scheme *kanthor) sign(i int, data string) string {
+	return signature.Sign(scheme.keys[i], data)
+}
+
+func (scheme *kanthor) verify(data, signatures string) error {
+	return signature.VerifyAny(scheme.keys, data, signatures)
+}
+
+func (scheme *kanthor) timestamp(ts string) (time.Time, error) {
+	msec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrMessageTimestampMalformed
+	}
+	return time.UnixMilli(msec), nil
+}
+
+func (scheme *kanthor) format(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+func (scheme *kanthor) divider() string {
+	return signature.SignaturesDivider
+}